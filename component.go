@@ -2,16 +2,32 @@ package gong
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"maps"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"unicode"
 
 	"github.com/a-h/templ"
 )
 
 const (
 	idDelimeter = "_"
+	// rootComponentID is the ID given to a route's component when it has no explicit ID.
+	rootComponentID = "root"
+	// notFoundComponentID and errorComponentID are the IDs given to a route's
+	// fallback components when they have no explicit ID.
+	notFoundComponentID = "notfound"
+	errorComponentID    = "error"
+)
+
+var (
+	componentType      = reflect.TypeFor[Component]()
+	componentInstances atomic.Uint64
 )
 
 type gongComponent struct {
@@ -21,33 +37,44 @@ type gongComponent struct {
 	head     Head
 	id       string
 	children map[string]Component
+	fallback templ.Component
+	err      error
+	// instance identifies the component created by NewComponent across the
+	// copies made when it is given an ID or loader.
+	instance uint64
 }
 
 // New creates a new Component instance with the specified view.
 // It automatically scans the view for any child components and sets up
 // optional interfaces (Loader, Action, Head) if the view implements them.
+//
+// Component IDs are derived from the component's position in the tree:
+// a child component stored in a struct field of the view is identified by the
+// field name, an element of a slice, array or map field by the field name and
+// its index or key, and a route's component by the route it is attached to.
+// Use WithComponentID to choose an explicit ID instead.
 func NewComponent(view View, opts ...ComponentOption) Component {
 	component := gongComponent{
-		id:       nextComponentID(),
 		view:     view,
 		children: make(map[string]Component),
+		instance: componentInstances.Add(1),
 	}
 
 	for _, opt := range opts {
 		component = opt(component)
 	}
 
-	component.scanViewForActions()
+	component = component.scanViewForActions()
 
-	if loader, ok := view.(Loader); ok {
+	if loader, ok := component.view.(Loader); ok {
 		component.loader = loader
 	}
 
-	if action, ok := view.(Action); ok {
+	if action, ok := component.view.(Action); ok {
 		component.action = action
 	}
 
-	if head, ok := view.(Head); ok {
+	if head, ok := component.view.(Head); ok {
 		component.head = head
 	}
 
@@ -56,8 +83,13 @@ func NewComponent(view View, opts ...ComponentOption) Component {
 
 // Render writes the component's HTML representation to the provided writer.
 // It handles both normal rendering and action execution based on the context.
-// Returns an error if rendering fails.
+// Returns an error if rendering fails or if the component has no ID, which
+// happens when it is created while rendering instead of stored in its parent's view.
 func (component gongComponent) Render(ctx context.Context, w io.Writer) error {
+	if component.id == "" {
+		return fmt.Errorf("gong: component %T has no id: store it in a field of its parent's view or set one with WithComponentID", component.view)
+	}
+
 	gCtx := getContext(ctx)
 	gCtx.Component = component
 
@@ -67,7 +99,7 @@ func (component gongComponent) Render(ctx context.Context, w io.Writer) error {
 		gCtx.ComponentID += idDelimeter + component.id
 	}
 
-	return render(ctx, gCtx, w, component.view.View())
+	return renderWithFallback(ctx, gCtx, w, component.view.View(), component.fallback)
 }
 
 func (component gongComponent) View() templ.Component {
@@ -81,7 +113,7 @@ func (component gongComponent) Action() templ.Component {
 		}
 		gCtx := getContext(ctx)
 		gCtx.Component = component
-		return render(ctx, gCtx, w, component.action.Action())
+		return renderWithFallback(ctx, gCtx, w, component.action.Action(), component.fallback)
 	})
 }
 
@@ -92,9 +124,11 @@ func (component gongComponent) Loader(ctx context.Context) any {
 	return component.loader.Loader(ctx)
 }
 
+// Head returns the head elements contributed by the component's view,
+// or an empty component if the view does not implement Head.
 func (component gongComponent) Head() templ.Component {
 	if component.head == nil {
-		return defaultHead()
+		return templ.NopComponent
 	}
 	return component.head.Head()
 }
@@ -141,36 +175,185 @@ func (component gongComponent) WithLoaderData(data any) Component {
 // It takes a gongComponent and returns a modified one.
 type ComponentOption func(gongComponent) gongComponent
 
-// WithID sets a custom ID for the component.
-// This ID is used for component identification and event handling.
-// Returns the modified component for method chaining.
-func withID(id string) ComponentOption {
+// WithComponentID sets an explicit ID for the component.
+// This ID is used for component identification and event handling instead of
+// the ID derived from the component's position in the tree.
+// It must be unique among its siblings and may not contain an underscore.
+func WithComponentID(id string) ComponentOption {
 	return func(gc gongComponent) gongComponent {
 		gc.id = id
 		return gc
 	}
 }
 
-func (component gongComponent) scanViewForActions() {
+// WithErrorFallback makes the component an error boundary: if its View or Action
+// returns an error, the error is reported to the ErrorHandler and fallback is
+// rendered in its place. The error is available to fallback through RenderError.
+func WithErrorFallback(fallback templ.Component) ComponentOption {
+	return func(gc gongComponent) gongComponent {
+		gc.fallback = fallback
+		return gc
+	}
+}
+
+// scanViewForActions registers every child component stored in an exported
+// struct field of the view, either directly or as an element of a slice, array
+// or map field. Children without an explicit ID are given the field name as
+// their ID, followed by "-" and the index or key for elements, such as
+// "Items-0", and the view is updated to hold the identified children.
+func (component gongComponent) scanViewForActions() gongComponent {
 	v := reflect.ValueOf(component.view)
 	t := v.Type()
-	if t.Kind() == reflect.Struct {
-		for i := range t.NumField() {
-			field := v.Field(i)
-			if !field.CanInterface() {
+	if t.Kind() != reflect.Struct {
+		return component
+	}
+
+	view := reflect.New(t).Elem()
+	view.Set(v)
+	for i := range t.NumField() {
+		field := view.Field(i)
+		if !field.CanInterface() {
+			continue
+		}
+		name := t.Field(i).Name
+		switch field.Kind() {
+		case reflect.Slice:
+			if field.IsNil() || !holdsComponents(field.Type().Elem()) {
 				continue
 			}
-			if child, ok := field.Interface().(Component); ok {
-				component.children[child.ID()] = child
+			// Copy the slice so the caller's backing array is not modified.
+			elems := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+			reflect.Copy(elems, field)
+			field.Set(elems)
+			fallthrough
+		case reflect.Array:
+			if !holdsComponents(field.Type().Elem()) {
+				continue
 			}
+			for j := range field.Len() {
+				component.registerChild(field.Index(j), name+"-"+strconv.Itoa(j))
+			}
+		case reflect.Map:
+			if field.IsNil() || !holdsComponents(field.Type().Elem()) {
+				continue
+			}
+			elems := reflect.MakeMapWithSize(field.Type(), field.Len())
+			iter := field.MapRange()
+			for iter.Next() {
+				elem := reflect.New(field.Type().Elem()).Elem()
+				elem.Set(iter.Value())
+				component.registerChild(elem, fmt.Sprintf("%s-%v", name, iter.Key()))
+				elems.SetMapIndex(iter.Key(), elem)
+			}
+			field.Set(elems)
+		default:
+			component.registerChild(field, name)
+		}
+	}
+	component.view = view.Interface().(View)
+	return component
+}
+
+// registerChild registers the component held by v, if any, giving it the ID
+// if it has none yet and updating v to hold the identified child.
+func (component *gongComponent) registerChild(v reflect.Value, id string) {
+	child, ok := v.Interface().(Component)
+	if !ok {
+		return
+	}
+	if gc, ok := child.(gongComponent); ok && gc.id == "" {
+		gc.id = id
+		child = gc
+		v.Set(reflect.ValueOf(child))
+	}
+	if _, ok := component.children[child.ID()]; ok && component.err == nil {
+		component.err = fmt.Errorf("gong: duplicate component id %q in %s", child.ID(), reflect.TypeOf(component.view))
+	}
+	component.children[child.ID()] = child
+}
+
+// holdsComponents reports whether values of type t can be components.
+func holdsComponents(t reflect.Type) bool {
+	return t.Kind() == reflect.Interface || t.Implements(componentType)
+}
+
+// withDefaultID returns the component with the given ID if it has no ID yet.
+func withDefaultID(component Component, id string) Component {
+	if gc, ok := component.(gongComponent); ok && gc.id == "" {
+		gc.id = id
+		return gc
+	}
+	return component
+}
+
+// componentMatch is a component found in a component tree, with its ID path
+// as used in the Gong-Component-ID header.
+type componentMatch struct {
+	path      string
+	component Component
+}
+
+// findComponent returns every occurrence of target in the component tree.
+// Components created with NewComponent are matched by identity, which is kept
+// when they are given an ID by their parent, and other components by ID.
+func findComponent(component Component, target Component) []componentMatch {
+	var matches []componentMatch
+	if sameComponent(component, target) {
+		matches = append(matches, componentMatch{path: component.ID(), component: component})
+	}
+	gc, ok := component.(gongComponent)
+	if !ok {
+		return matches
+	}
+	for _, childID := range slices.Sorted(maps.Keys(gc.children)) {
+		for _, match := range findComponent(gc.children[childID], target) {
+			match.path = component.ID() + idDelimeter + match.path
+			matches = append(matches, match)
 		}
 	}
+	return matches
 }
 
-var _nextComponentID = 0
+// sameComponent reports whether a and b are the same component.
+func sameComponent(a Component, b Component) bool {
+	ga, aok := a.(gongComponent)
+	gb, bok := b.(gongComponent)
+	if aok || bok {
+		return aok && bok && ga.instance == gb.instance
+	}
+	return a.ID() == b.ID()
+}
 
-func nextComponentID() string {
-	id := strconv.Itoa(_nextComponentID)
-	_nextComponentID++
-	return id
+// validateComponent checks the component tree for duplicate or invalid IDs.
+func validateComponent(component Component) error {
+	gc, ok := component.(gongComponent)
+	if !ok {
+		return nil
+	}
+	if gc.err != nil {
+		return gc.err
+	}
+	if !validComponentID(gc.id) {
+		return fmt.Errorf("gong: invalid component id %q", gc.id)
+	}
+	for _, child := range gc.children {
+		if err := validateComponent(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validComponentID reports whether id can be used in Gong-Component-ID
+// headers and element IDs.
+func validComponentID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, c := range id {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '-' {
+			return false
+		}
+	}
+	return true
 }
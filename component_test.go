@@ -1,8 +1,12 @@
 package gong
 
 import (
+	"context"
+	"errors"
+	"io"
 	"testing"
 
+	"github.com/a-h/templ"
 	"github.com/troygilman/gong/internal/assert"
 )
 
@@ -11,11 +15,18 @@ func TestComponentRenderView(t *testing.T) {
 		view: testTemplComponent{text: "view"},
 	}
 
-	component := NewComponent(mock)
+	component := NewComponent(mock, WithComponentID("mock"))
 
 	testRender(t, component, gongContext{}, "view")
 }
 
+func TestComponentRenderView_withoutID(t *testing.T) {
+	component := NewComponent(testComponent{})
+
+	err := render(context.Background(), gongContext{}, io.Discard, component)
+	assert.Err(t, err)
+}
+
 func TestComponentRenderAction(t *testing.T) {
 	mock := testComponent{
 		action: testTemplComponent{text: "action"},
@@ -40,7 +51,7 @@ func TestComponentRenderAction_withLoader(t *testing.T) {
 func TestComponentFind(t *testing.T) {
 	mock := testComponent{}
 
-	component := NewComponent(mock, withID("mock"))
+	component := NewComponent(mock, WithComponentID("mock"))
 
 	foundComponent, ok := component.Find("mock")
 
@@ -49,12 +60,133 @@ func TestComponentFind(t *testing.T) {
 }
 
 func TestComponentFind_withNestedComponent(t *testing.T) {
-	child := NewComponent(testComponent{}, withID("mock"))
+	child := NewComponent(testComponent{}, WithComponentID("mock"))
 
-	component := NewComponent(testParentComponent{Child: child}, withID("parent"))
+	component := NewComponent(testParentComponent{Child: child}, WithComponentID("parent"))
 
 	foundComponent, ok := component.Find("parent_mock")
 
 	assert.Equals(t, true, ok)
 	assert.Equals(t, child, foundComponent)
 }
+
+func TestComponentID_fromFieldName(t *testing.T) {
+	child := NewComponent(testComponent{})
+
+	node := NewRoute("/", NewComponent(testParentComponent{Child: child})).newNode(nil)
+
+	foundComponent, ok := node.route.component.Find("root_Child")
+
+	assert.Equals(t, true, ok)
+	assert.Equals(t, "Child", foundComponent.ID())
+	assert.Equals(t, "", child.ID())
+}
+
+type testTwoChildComponent struct {
+	First  Component
+	Second Component
+}
+
+func (c testTwoChildComponent) View() templ.Component {
+	return nil
+}
+
+type testListComponent struct {
+	Items  []Component
+	Panels map[string]Component
+}
+
+func (c testListComponent) View() templ.Component {
+	return nil
+}
+
+func TestComponentID_fromElements(t *testing.T) {
+	items := []Component{NewComponent(testComponent{}), NewComponent(testComponent{})}
+	component := NewComponent(testListComponent{
+		Items:  items,
+		Panels: map[string]Component{"settings": NewComponent(testComponent{})},
+	})
+
+	node := NewRoute("/", component).newNode(nil)
+	assert.NoErr(t, node.validate())
+
+	for _, id := range []string{"root_Items-0", "root_Items-1", "root_Panels-settings"} {
+		_, ok := node.route.component.Find(id)
+		assert.Equals(t, true, ok)
+	}
+	assert.Equals(t, "", items[0].ID())
+}
+
+func TestComponentID_invalidMapKey(t *testing.T) {
+	component := NewComponent(testListComponent{
+		Panels: map[string]Component{"user_settings": NewComponent(testComponent{})},
+	})
+
+	node := NewRoute("/", component).newNode(nil)
+
+	assert.Err(t, node.validate())
+}
+
+func TestComponentID_duplicate(t *testing.T) {
+	component := NewComponent(testTwoChildComponent{
+		First:  NewComponent(testComponent{}, WithComponentID("same")),
+		Second: NewComponent(testComponent{}, WithComponentID("same")),
+	})
+
+	node := NewRoute("/", component).newNode(nil)
+
+	assert.Err(t, node.validate())
+}
+
+func TestComponentID_invalid(t *testing.T) {
+	node := NewRoute("/", NewComponent(testComponent{}, WithComponentID("bad_id"))).newNode(nil)
+
+	assert.Err(t, node.validate())
+}
+
+func TestComponentRenderView_withErrorFallback(t *testing.T) {
+	var handled error
+	mock := testComponent{
+		view: Error(errors.New("broken")),
+	}
+
+	component := NewComponent(mock, WithComponentID("mock"), WithErrorFallback(testTemplComponent{text: "fallback"}))
+
+	testRender(t, component, gongContext{
+		ErrorHandler: func(ctx context.Context, err error) {
+			handled = err
+		},
+	}, "fallback")
+	assert.Equals(t, "broken", handled.Error())
+}
+
+func TestComponentRenderAction_withErrorFallback(t *testing.T) {
+	mock := testComponent{
+		action: Error(errors.New("broken")),
+	}
+
+	component := NewComponent(mock, WithErrorFallback(testTemplComponent{text: "fallback"}))
+
+	testRender(t, component.Action(), gongContext{ErrorHandler: func(context.Context, error) {}}, "fallback")
+}
+
+func TestErrorBoundary(t *testing.T) {
+	boundary := RenderFunc(func(ctx context.Context, w io.Writer) error {
+		if _, err := io.WriteString(w, "before "); err != nil {
+			return err
+		}
+		children := RenderFunc(func(ctx context.Context, w io.Writer) error {
+			if _, err := io.WriteString(w, "partial"); err != nil {
+				return err
+			}
+			return errors.New("broken")
+		})
+		fallback := RenderFunc(func(ctx context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, "fallback: "+RenderError(ctx).Error())
+			return err
+		})
+		return ErrorBoundary(fallback).Render(templ.WithChildren(ctx, children), w)
+	})
+
+	testRender(t, boundary, gongContext{ErrorHandler: func(context.Context, error) {}}, "before fallback: broken")
+}
@@ -22,8 +22,17 @@ type gongContext struct {
 	Path            string
 	Action          bool
 	Link            bool
-	ErrorHandler    ErrorHandler
 	RenderedPath    string
+	NotFound        *routeNode
+	CSRFToken       string
+	Session         *SessionData
+	FieldErrors     ValidationErrors
+	MultipartMemory int64
+	BindLimits      BindLimits
+	OOB             string
+	OOBComponentID  string
+	Err             error
+	ErrorHandler    ErrorHandler
 }
 
 func getContext(ctx context.Context) gongContext {
@@ -0,0 +1,72 @@
+package gong
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+)
+
+const (
+	defaultCSRFCookieName = "gong_csrf"
+	csrfTokenLength       = 32
+)
+
+// CSRFConfig configures the CSRF protection of action requests.
+// Gong uses the double-submit cookie pattern: each client gets a random token
+// in a cookie, the same token is rendered into the hx-headers of every
+// element, and action requests with unsafe methods must send both.
+type CSRFConfig struct {
+	// CookieName is the name of the cookie holding the token. Defaults to "gong_csrf".
+	CookieName string
+	// Secure restricts the cookie to HTTPS connections.
+	Secure bool
+	// SameSite sets the SameSite attribute of the cookie. Defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+}
+
+// csrfToken returns the client's CSRF token from its cookie, or issues a new
+// token and sets the cookie on the response if the client has none.
+// The returned bool reports whether the token came from the request.
+func (config CSRFConfig) csrfToken(w http.ResponseWriter, r *http.Request) (string, bool, error) {
+	if cookie, err := r.Cookie(config.CookieName); err == nil && validCSRFToken(cookie.Value) {
+		return cookie.Value, true, nil
+	}
+	b := make([]byte, csrfTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", false, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	http.SetCookie(w, &http.Cookie{
+		Name:     config.CookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   config.Secure,
+		SameSite: config.SameSite,
+	})
+	return token, false, nil
+}
+
+// verify checks the CSRF token of an action request. Requests with safe
+// methods are not checked; other requests must send the token from the
+// client's cookie in the Gong-CSRF-Token header.
+func (config CSRFConfig) verify(r *http.Request, token string, fromCookie bool) error {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return nil
+	}
+	if !fromCookie {
+		return fmt.Errorf("%w: missing %s cookie", ErrInvalidCSRFToken, config.CookieName)
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(HeaderGongCSRFToken)), []byte(token)) != 1 {
+		return fmt.Errorf("%w: %s header does not match cookie", ErrInvalidCSRFToken, HeaderGongCSRFToken)
+	}
+	return nil
+}
+
+func validCSRFToken(token string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	return err == nil && len(b) == csrfTokenLength
+}
@@ -0,0 +1,172 @@
+package gong
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// DocumentFunc renders the HTML document shell around a full page render.
+// The head component renders the contents of the <head> element: the elements
+// Gong needs followed by the Head of every route on the active route chain.
+// The body component renders the outlet containing the active routes.
+type DocumentFunc func(head templ.Component, body templ.Component) templ.Component
+
+// documentHead returns the contents of the document's <head> element.
+// It must be rendered by the root route's component.
+func documentHead(scripts []script) templ.Component {
+	return RenderFunc(func(ctx context.Context, w io.Writer) error {
+		elements, err := activeHeadElements(ctx, scripts)
+		if err != nil {
+			return err
+		}
+		for _, element := range elements {
+			if _, err := io.WriteString(w, element.html()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// headOOB returns the title and meta elements of the active route chain as
+// out of band swaps, so they are updated when a link swaps the page's outlets.
+// It must be rendered with the root route node in the context.
+func headOOB() templ.Component {
+	return RenderFunc(func(ctx context.Context, w io.Writer) error {
+		elements, err := activeHeadElements(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for _, element := range elements {
+			if element.key == "" {
+				continue
+			}
+			if _, err := io.WriteString(w, element.withAttr(`hx-swap-oob="outerHTML"`)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// activeHeadElements renders the default head followed by the Head of every
+// route on the active route chain and merges their elements. The <title> and
+// each <meta> key appear once, with the content of the innermost route that
+// sets them, at the position where they first appeared.
+func activeHeadElements(ctx context.Context, scripts []script) ([]headElement, error) {
+	gCtx := getContext(ctx)
+	heads := []templ.Component{defaultHead(scripts)}
+	nodes := []*routeNode{gCtx.Node}
+	for _, node := range gCtx.Node.chain(gCtx.RouteID) {
+		heads = append(heads, node.route.component.Head())
+		nodes = append(nodes, node)
+	}
+
+	var elements []headElement
+	keys := make(map[string]int)
+	for i, head := range heads {
+		gCtx.Node = nodes[i]
+		buffer := new(bytes.Buffer)
+		if err := render(ctx, gCtx, buffer, head); err != nil {
+			return nil, err
+		}
+		for _, element := range parseHead(buffer.String()) {
+			if index, ok := keys[element.key]; ok && element.key != "" {
+				elements[index] = element
+				continue
+			}
+			if element.key != "" {
+				keys[element.key] = len(elements)
+			}
+			elements = append(elements, element)
+		}
+	}
+	return elements, nil
+}
+
+var (
+	headElementRegexp = regexp.MustCompile(`(?is)<title\b[^>]*>.*?</title\s*>|<meta\b[^>]*>`)
+	headAttrRegexp    = regexp.MustCompile(`([a-zA-Z_:][-a-zA-Z0-9_:.]*)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'=<>]+)))?`)
+	headIDRegexp      = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+)
+
+// headElement is a fragment of rendered head content. Title and meta elements
+// are keyed so that inner routes can replace them; other content has no key.
+type headElement struct {
+	key    string
+	source string
+	// tagEnd is the offset in source just after the tag name, where attributes can be inserted.
+	tagEnd int
+}
+
+// parseHead splits rendered head content into title and meta elements and
+// the raw content between them. Keyed elements without an id are given one
+// so they can be targeted by out of band swaps.
+func parseHead(source string) []headElement {
+	var elements []headElement
+	last := 0
+	for _, match := range headElementRegexp.FindAllStringIndex(source, -1) {
+		if match[0] > last {
+			elements = append(elements, headElement{source: source[last:match[0]]})
+		}
+		elements = append(elements, newHeadElement(source[match[0]:match[1]]))
+		last = match[1]
+	}
+	if last < len(source) {
+		elements = append(elements, headElement{source: source[last:]})
+	}
+	return elements
+}
+
+func newHeadElement(source string) headElement {
+	tagEnd := len("<meta")
+	if strings.HasPrefix(strings.ToLower(source), "<title") {
+		tagEnd = len("<title")
+	}
+	element := headElement{
+		source: source,
+		tagEnd: tagEnd,
+	}
+
+	attrs := make(map[string]string)
+	startTag := source[tagEnd:strings.IndexByte(source, '>')]
+	for _, match := range headAttrRegexp.FindAllStringSubmatch(startTag, -1) {
+		attrs[strings.ToLower(match[1])] = match[2] + match[3] + match[4]
+	}
+
+	if tagEnd == len("<title") {
+		element.key = "title"
+	} else if _, ok := attrs["charset"]; ok {
+		element.key = "meta-charset"
+	} else {
+		for _, attr := range []string{"name", "property", "http-equiv", "itemprop"} {
+			if value, ok := attrs[attr]; ok {
+				element.key = "meta-" + attr + "-" + strings.ToLower(value)
+				break
+			}
+		}
+	}
+
+	if _, ok := attrs["id"]; element.key != "" && !ok {
+		id := "gong_head_" + strings.Trim(headIDRegexp.ReplaceAllString(element.key, "-"), "-")
+		element.source = element.withAttr(`id="` + id + `"`)
+	}
+	return element
+}
+
+func (element headElement) html() string {
+	return element.source
+}
+
+// withAttr returns the element's HTML with the attribute added to its start tag.
+func (element headElement) withAttr(attr string) string {
+	if element.key == "" {
+		return element.source
+	}
+	return element.source[:element.tagEnd] + " " + attr + element.source[element.tagEnd:]
+}
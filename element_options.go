@@ -1,18 +1,31 @@
 package gong
 
-import "github.com/a-h/templ"
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+const multipartEncoding = "multipart/form-data"
 
 type elementConfig struct {
-	id      string
-	method  string
-	swap    string
-	target  string
-	headers []string
-	trigger string
-	oob     bool
-	attrs   templ.Attributes
-	classes templ.CSSClasses
-	node    *routeNode
+	id        string
+	method    string
+	swap      string
+	target    string
+	headers   []string
+	params    []string
+	trigger   string
+	subscribe string
+	listen    []string
+	oob       string
+	encoding  string
+	progress  string
+	attrs     templ.Attributes
+	classes   templ.CSSClasses
+	node      *routeNode
 }
 
 type ElementOption func(elementConfig) elementConfig
@@ -38,6 +51,15 @@ func WithHeaders(headers ...string) ElementOption {
 	}
 }
 
+// WithParams sets the wildcard values used when a Link refers to a named route.
+// Params are alternating wildcard names and values, as accepted by URL.
+func WithParams(params ...string) ElementOption {
+	return func(c elementConfig) elementConfig {
+		c.params = params
+		return c
+	}
+}
+
 func WithAttrs(attrs templ.Attributes) ElementOption {
 	return func(c elementConfig) elementConfig {
 		c.attrs = attrs
@@ -73,7 +95,47 @@ func WithTrigger(trigger string) ElementOption {
 	}
 }
 
-func withOOB(oob bool) ElementOption {
+// WithListen makes a Target or Form send its request whenever one of the events
+// is emitted with Emit, in addition to its own trigger.
+func WithListen(events ...string) ElementOption {
+	return func(c elementConfig) elementConfig {
+		c.listen = append(c.listen, events...)
+		return c
+	}
+}
+
+// WithSubscribe subscribes a Target to a topic. Components published to the
+// topic with Server.Publish are pushed to the client over server-sent events
+// and swapped into the Target. This requires the htmx SSE extension.
+func WithSubscribe(topic string) ElementOption {
+	return func(c elementConfig) elementConfig {
+		c.subscribe = topic
+		return c
+	}
+}
+
+// WithMultipart makes a Form submit its fields as multipart/form-data,
+// which is required to upload files from file inputs.
+func WithMultipart() ElementOption {
+	return func(c elementConfig) elementConfig {
+		c.encoding = multipartEncoding
+		return c
+	}
+}
+
+// WithUploadProgress makes a multipart Form report the progress of its
+// uploads by setting the value attribute of the element matched by the
+// selector, typically a <progress max="100"> element, to the percentage sent.
+func WithUploadProgress(selector string) ElementOption {
+	return func(c elementConfig) elementConfig {
+		c.encoding = multipartEncoding
+		c.progress = selector
+		return c
+	}
+}
+
+// withOOB makes the element an out of band swap with the given strategy.
+func withOOB(oob string) ElementOption {
 	return func(c elementConfig) elementConfig {
 		c.oob = oob
 		return c
@@ -86,3 +148,23 @@ func withNode(node *routeNode) ElementOption {
 		return c
 	}
 }
+
+// uploadProgress returns the script that updates the progress element from
+// the htmx:xhr:progress event of an upload.
+func (c elementConfig) uploadProgress() templ.ComponentScript {
+	selector, _ := json.Marshal(c.progress)
+	return templ.JSUnsafeFuncCall(fmt.Sprintf("htmx.find(%s).setAttribute('value', event.detail.loaded / event.detail.total * 100)", selector))
+}
+
+// triggers returns the hx-trigger value combining the element's trigger with
+// a trigger for each event it listens for.
+func (c elementConfig) triggers() string {
+	var triggers []string
+	if c.trigger != "" && (c.trigger != TriggerNone || len(c.listen) == 0) {
+		triggers = append(triggers, c.trigger)
+	}
+	for _, event := range c.listen {
+		triggers = append(triggers, TriggerOn(event))
+	}
+	return strings.Join(triggers, ", ")
+}
@@ -0,0 +1,56 @@
+package gong
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/troygilman/gong/internal/bind"
+)
+
+// Errors returned while resolving and rendering a request.
+// They can be matched with errors.Is inside an ErrorHandler.
+var (
+	// ErrRouteNotFound indicates that no route matches the request.
+	ErrRouteNotFound = errors.New("gong: route not found")
+	// ErrComponentNotFound indicates that the component targeted by an action does not exist in its route.
+	ErrComponentNotFound = errors.New("gong: component not found")
+	// ErrBadGongHeaders indicates that the Gong request headers are missing or malformed.
+	ErrBadGongHeaders = errors.New("gong: bad gong headers")
+	// ErrInvalidCSRFToken indicates that an action request failed CSRF verification.
+	ErrInvalidCSRFToken = errors.New("gong: invalid CSRF token")
+	// ErrForbidden indicates that a Guard denied the request.
+	ErrForbidden = errors.New("gong: forbidden")
+	// ErrMalformedForm indicates that Bind could not parse the request body as a form.
+	ErrMalformedForm = errors.New("gong: malformed form")
+	// ErrUnknownRouteName indicates that URL or Link was given a route name
+	// that no route has. It is a bug in the view, so it maps to status 500.
+	ErrUnknownRouteName = errors.New("gong: unknown route name")
+)
+
+// BindLimitError is returned by Bind when the form exceeds the server's BindLimits.
+type BindLimitError = bind.LimitError
+
+// BindErrors is returned by Bind when form values cannot be converted to the
+// types of their fields. It lists a *BindFieldError for every such value.
+type BindErrors = bind.Errors
+
+// BindFieldError describes a form value that Bind could not convert, with the
+// bracket path of its field, the Go type it was bound to, and the raw value.
+type BindFieldError = bind.FieldError
+
+// StatusCode returns the HTTP status code that Gong responds with for the given error.
+// Errors returned by Bind for invalid forms map to 400 Bad Request, and
+// unknown errors map to 500 Internal Server Error.
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, ErrBadGongHeaders), errors.Is(err, ErrMalformedForm),
+		errors.As(err, new(*BindLimitError)), errors.As(err, new(BindErrors)), errors.As(err, new(ValidationErrors)):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrInvalidCSRFToken), errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrRouteNotFound), errors.Is(err, ErrComponentNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,68 @@
+package gong
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Event is a named event carrying a payload of type T.
+// Declaring events as package variables keeps emitters and listeners in sync:
+//
+//	var UserCreated = gong.NewEvent[User]("user.created")
+type Event[T any] struct {
+	name string
+}
+
+// NewEvent creates an event with the given name.
+func NewEvent[T any](name string) Event[T] {
+	return Event[T]{name: name}
+}
+
+// Name returns the name of the event, for use with WithListen.
+func (e Event[T]) Name() string {
+	return e.name
+}
+
+// Emit emits the event with the payload for the current client. See Emit.
+func (e Event[T]) Emit(ctx context.Context, payload T) error {
+	return Emit(ctx, e.name, payload)
+}
+
+// Emit emits an event for the current client with Trigger, which refreshes
+// every rendered element listening for it with WithListen.
+func Emit(ctx context.Context, event string, payload any) error {
+	return Trigger(ctx, event, payload)
+}
+
+// TriggerOn creates an HTMX trigger that fires when the event is dispatched
+// anywhere in the document, such as events emitted with Emit.
+func TriggerOn(event string) string {
+	return event + " from:body"
+}
+
+// addTrigger adds an event to a trigger header, converting an existing
+// comma separated list of event names to the JSON object form.
+func addTrigger(header http.Header, key string, event string, payload any) error {
+	events := make(map[string]json.RawMessage)
+	if existing := header.Get(key); existing != "" {
+		if err := json.Unmarshal([]byte(existing), &events); err != nil {
+			for _, name := range strings.Split(existing, ",") {
+				events[strings.TrimSpace(name)] = json.RawMessage("null")
+			}
+		}
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("gong: encoding payload of event %q: %w", event, err)
+	}
+	events[event] = data
+	data, err = json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	header.Set(key, string(data))
+	return nil
+}
@@ -0,0 +1,70 @@
+package gong
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/troygilman/gong/internal/assert"
+	"github.com/troygilman/gong/internal/response_writer"
+)
+
+func TestEmit(t *testing.T) {
+	writer := response_writer.NewResponseWriter(httptest.NewRecorder())
+	writer.Header().Set(HeaderHXTrigger, "refresh")
+	ctx := setContext(context.Background(), gongContext{Writer: writer})
+
+	userCreated := NewEvent[string]("user.created")
+	assert.NoErr(t, userCreated.Emit(ctx, "bob"))
+	assert.NoErr(t, Emit(ctx, "count", 2))
+
+	assert.Equals(t, `{"count":2,"refresh":null,"user.created":"bob"}`, writer.Header().Get(HeaderHXTrigger))
+}
+
+func TestElementTriggers(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []ElementOption
+		expected string
+	}{
+		{
+			name:     "trigger only",
+			opts:     []ElementOption{WithTrigger(TriggerLoad)},
+			expected: "load",
+		},
+		{
+			name:     "listen replaces none",
+			opts:     []ElementOption{WithTrigger(TriggerNone), WithListen("user.created")},
+			expected: "user.created from:body",
+		},
+		{
+			name:     "trigger and listen",
+			opts:     []ElementOption{WithTrigger(TriggerLoad), WithListen("a", "b")},
+			expected: "load, a from:body, b from:body",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := elementConfig{method: http.MethodGet}
+			for _, opt := range test.opts {
+				c = opt(c)
+			}
+			assert.Equals(t, test.expected, c.triggers())
+		})
+	}
+}
+
+func TestFormListen(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: Form(WithListen("user.created")),
+	})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), `hx-trigger="submit, user.created from:body"`))
+}
@@ -14,8 +14,9 @@ import (
 )
 
 type listView struct {
-	UserComponent gong.Component
-	db            *userDatabase
+	UserComponent      gong.Component
+	UserCountComponent gong.Component
+	db                 *userDatabase
 }
 
 func (view listView) Action() templ.Component {
@@ -56,6 +57,9 @@ func (view listView) Action() templ.Component {
 			if err := view.db.Create(user); err != nil {
 				return nil
 			}
+			if err := userCreated.Emit(ctx, user.name); err != nil {
+				return err
+			}
 			templ_7745c5c3_Err = view.UserComponent.WithLoaderData(user).Render(ctx, templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
@@ -90,6 +94,10 @@ func (view listView) View() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
+		templ_7745c5c3_Err = view.UserCountComponent.Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
 		templ_7745c5c3_Var3 := templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 			templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
 			templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
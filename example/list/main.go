@@ -15,13 +15,14 @@ func main() {
 	svr.Route(gong.NewRoute("/", gong.NewComponent(homeView{}),
 		gong.WithChildren(
 			gong.NewRoute("users", gong.NewComponent(listView{
-				db:            db,
-				UserComponent: userComponent,
+				db:                 db,
+				UserComponent:      userComponent,
+				UserCountComponent: gong.NewComponent(userCountView{db: db}),
 			})),
 			gong.NewRoute("user/{name}/", gong.NewComponent(testView{
 				db:            db,
 				UserComponent: userComponent,
-			})),
+			}), gong.WithName("user.detail")),
 		),
 	))
 
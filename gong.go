@@ -13,6 +13,7 @@ const (
 	HeaderGongRequestType = "Gong-Request-Type"
 	HeaderGongComponentID = "Gong-Component-ID"
 	HeaderGongRouteID     = "Gong-Route-ID"
+	HeaderGongCSRFToken   = "Gong-CSRF-Token"
 )
 
 // Request type constants used by Gong
@@ -26,8 +27,9 @@ const (
 	// TriggerNone indicates no automatic trigger
 	TriggerNone = "none"
 	// TriggerLoad indicates the component should update on page load
-	TriggerLoad  = "load"
-	TriggerClick = "click"
+	TriggerLoad   = "load"
+	TriggerClick  = "click"
+	TriggerSubmit = "submit"
 )
 
 // HTMX swap constants for content updates
@@ -62,7 +64,8 @@ type Action interface {
 }
 
 // Head is an interface for components that can provide head elements.
-// It defines the method for getting head elements.
+// It defines the method for getting head elements. The returned component
+// renders elements placed inside the document's <head>, not the <head> element itself.
 type Head interface {
 	Head() templ.Component
 }
@@ -111,13 +114,13 @@ type Component interface {
 // TriggerAfterSwap creates an HTMX event trigger that fires after a swap operation
 // completes for an element with the specified ID.
 func TriggerAfterSwap(id string) string {
-	return fmt.Sprintf("htmx:afterSwap[detail.target.id === '%s'] from:body", id)
+	return TriggerOn(fmt.Sprintf("htmx:afterSwap[detail.target.id === '%s']", id))
 }
 
 // TriggerAfterSwapOOB creates an HTMX event trigger that fires after an out-of-band
 // swap operation completes for an element with the specified ID.
 func TriggerAfterSwapOOB(id string) string {
-	return fmt.Sprintf("htmx:oobAfterSwap[detail.target.id === '%s'] from:body", id)
+	return TriggerOn(fmt.Sprintf("htmx:oobAfterSwap[detail.target.id === '%s']", id))
 }
 
 // Error creates a templ.Component that returns the provided error when rendered.
@@ -128,6 +131,23 @@ func Error(err error) templ.Component {
 	})
 }
 
-// ErrorHandler is a function type for handling errors that occur during component rendering.
-// It provides a way to implement custom error handling strategies.
+// ErrorBoundary creates a templ.Component that renders its children, or the
+// fallback in their place if rendering them returns an error. The error is
+// reported to the ErrorHandler and is available to the fallback through RenderError,
+// so one failing part of the page does not fail the whole response.
+// A nil fallback renders nothing in place of the children.
+func ErrorBoundary(fallback templ.Component) templ.Component {
+	if fallback == nil {
+		fallback = templ.NopComponent
+	}
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		children := templ.GetChildren(ctx)
+		ctx = templ.ClearChildren(ctx)
+		return renderWithFallback(ctx, getContext(ctx), w, children, fallback)
+	})
+}
+
+// ErrorHandler is a function type for handling errors that occur during request processing.
+// It is called before the error response is sent, with the status code already set
+// from StatusCode, so it can log the error, set headers, or redirect.
 type ErrorHandler func(context.Context, error)
@@ -0,0 +1,73 @@
+package gong
+
+import (
+	"context"
+	"errors"
+	"log"
+	"maps"
+	"net/http"
+
+	"github.com/troygilman/gong/internal/response_writer"
+)
+
+// Guard decides whether a request may render or act on a route.
+// It returns nil to let the request through, an error from RedirectTo to send
+// the client to another path, ErrForbidden to respond with 403 (Forbidden),
+// or ErrRouteNotFound to respond with 404 (Not Found).
+// Any other error is handled like an error from a View, with status 500.
+// Guards may set response headers with Header, Trigger, or Emit; the headers
+// are only sent if the guard lets the request through or redirects it.
+type Guard func(ctx context.Context) error
+
+// RedirectError is returned by a Guard to redirect the request to Path.
+type RedirectError struct {
+	Path string
+}
+
+// RedirectTo returns an error that makes a Guard redirect the request to the path.
+func RedirectTo(path string) error {
+	return &RedirectError{Path: path}
+}
+
+func (err *RedirectError) Error() string {
+	return "gong: redirect to " + err.Path
+}
+
+// guardHandler runs the guards before next, using the route resolved for the
+// request, so action requests are guarded by the route in their Gong-Route-ID
+// header rather than the route matching their URL.
+// Guards write to a buffered writer, so the headers they set are sent with the
+// response if they let the request through or redirect it, and discarded along
+// with the rest of the response if they fail.
+func (svr *Server) guardHandler(next http.Handler, guards []Guard) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gCtx := getContext(r.Context())
+		writer := response_writer.NewResponseWriter(w)
+		writer.BeforeFlush(func() error {
+			return gCtx.Session.save(writer)
+		})
+		guardCtx := gCtx
+		guardCtx.Writer = writer
+		ctx := setContext(r.Context(), guardCtx)
+		for _, guard := range guards {
+			err := guard(ctx)
+			if err == nil {
+				continue
+			}
+			var redirect *RedirectError
+			if errors.As(err, &redirect) {
+				_ = Redirect(ctx, redirect.Path)
+				if err := writer.Flush(); err != nil {
+					log.Println("gong: failed to write response:", err)
+				}
+				return
+			}
+			svr.handleError(w, r, gCtx, err)
+			return
+		}
+		header := w.Header()
+		clear(header)
+		maps.Copy(header, writer.Header())
+		next.ServeHTTP(w, r)
+	})
+}
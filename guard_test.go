@@ -0,0 +1,126 @@
+package gong
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/troygilman/gong/internal/assert"
+)
+
+func TestGuard(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		htmx     bool
+		status   int
+		location string
+		redirect string
+	}{
+		{name: "allow", err: nil, status: http.StatusOK},
+		{name: "forbidden", err: ErrForbidden, status: http.StatusForbidden},
+		{name: "not found", err: ErrRouteNotFound, status: http.StatusNotFound},
+		{name: "error", err: errors.New("boom"), status: http.StatusInternalServerError},
+		{name: "redirect", err: RedirectTo("/login"), status: http.StatusSeeOther, location: "/login"},
+		{name: "htmx redirect", err: RedirectTo("/login"), htmx: true, status: http.StatusOK, redirect: "/login"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svr := NewServer()
+			svr.Route(NewRoute("/", NewComponent(testLayoutComponent{}), WithChildren(
+				NewRoute("admin/", NewComponent(testLayoutComponent{}),
+					WithGuard(func(ctx context.Context) error {
+						return test.err
+					}),
+					WithChildren(
+						NewRoute("settings", NewComponent(testComponent{})),
+					),
+				),
+			)))
+
+			r := newTestRequest(http.MethodGet, "/admin/settings")
+			if test.htmx {
+				r.Header.Set(HeaderHXRequest, "true")
+			}
+			rec := httptest.NewRecorder()
+			svr.ServeHTTP(rec, r)
+
+			assert.Equals(t, test.status, rec.Code)
+			assert.Equals(t, test.location, rec.Header().Get("Location"))
+			assert.Equals(t, test.redirect, rec.Header().Get(HeaderHXRedirect))
+		})
+	}
+}
+
+func TestGuard_redirectSavesSession(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{}), WithGuard(func(ctx context.Context) error {
+		Session(ctx).Flash("notice", "please log in")
+		return RedirectTo("/login")
+	})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	assert.Equals(t, http.StatusSeeOther, rec.Code)
+	cookie := testSessionCookie(t, rec)
+	assert.Equals(t, true, cookie.Value != "")
+}
+
+func TestGuard_action(t *testing.T) {
+	var calls []string
+	guard := func(name string, err error) Guard {
+		return func(ctx context.Context) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{}),
+		WithGuard(guard("parent", nil)),
+		WithChildren(
+			NewRoute("admin", NewComponent(testComponent{
+				action: testTemplComponent{text: "action"},
+			}, WithComponentID("mock")), WithGuard(guard("child", ErrForbidden))),
+		),
+	))
+
+	r := newTestActionRequest(joinRouteID(routeSegment("/"), routeSegment("admin")), "mock")
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	assert.Equals(t, http.StatusForbidden, rec.Code)
+	assert.Equals(t, []string{"parent", "child"}, calls)
+}
+
+func TestGuard_headers(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		header string
+	}{
+		{name: "allow", err: nil, status: http.StatusOK, header: "checked"},
+		{name: "forbidden", err: ErrForbidden, status: http.StatusForbidden, header: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svr := NewServer()
+			svr.Route(NewRoute("/", NewComponent(testComponent{}), WithGuard(func(ctx context.Context) error {
+				Header(ctx).Set("X-Guard", "checked")
+				return test.err
+			})))
+
+			rec := httptest.NewRecorder()
+			svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+			assert.Equals(t, test.status, rec.Code)
+			assert.Equals(t, test.header, rec.Header().Get("X-Guard"))
+		})
+	}
+}
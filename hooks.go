@@ -2,22 +2,81 @@ package gong
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"slices"
 
 	"github.com/troygilman/gong/internal/bind"
 	"github.com/troygilman/gong/internal/util"
+	"github.com/troygilman/gong/internal/validate"
 )
 
 // Bind decodes form data from the current HTTP request into the provided destination.
 // It processes both URL query parameters and form values submitted via POST or GET requests.
 // The destination must be a pointer to a struct or map with appropriate "form" tags.
-// Returns an error if the form parsing or binding fails.
+// Multipart forms are parsed too, and their uploaded files bound to fields of
+// type *multipart.FileHeader or []*multipart.FileHeader.
+// After decoding, struct fields are validated against their "validate" tags.
+// Returns an error wrapping ErrMalformedForm if the form cannot be parsed,
+// a *BindLimitError if the form exceeds the server's BindLimits, BindErrors if
+// values cannot be converted to their fields' types, or ValidationErrors if
+// any field is invalid. All of them map to status 400 (Bad Request).
+// Values that fail to convert do not stop the other fields from being bound
+// and validated, and when both kinds of errors occur they are joined.
+// The messages of BindErrors and ValidationErrors are available through FieldError.
 func Bind(ctx context.Context, dest any) error {
 	r := Request(ctx)
-	if err := r.ParseForm(); err != nil {
+	gCtx := getContext(ctx)
+	var files map[string][]*multipart.FileHeader
+	if isMultipartRequest(r) {
+		maxMemory := gCtx.MultipartMemory
+		if maxMemory == 0 {
+			maxMemory = defaultMultipartMemory
+		}
+		if err := r.ParseMultipartForm(maxMemory); err != nil {
+			return fmt.Errorf("%w: %w", ErrMalformedForm, err)
+		}
+		files = r.MultipartForm.File
+	} else if err := r.ParseForm(); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformedForm, err)
+	}
+	limits := gCtx.BindLimits
+	if limits == (BindLimits{}) {
+		limits = bind.DefaultLimits
+	}
+
+	var bindErrs BindErrors
+	if err := bind.BindMultipart(r.Form, files, dest, limits); err != nil && !errors.As(err, &bindErrs) {
+		return err
+	}
+	var validationErrs ValidationErrors
+	if err := validate.Struct(dest); err != nil && !errors.As(err, &validationErrs) {
 		return err
 	}
-	return bind.Bind(r.Form, dest)
+
+	if gCtx.FieldErrors != nil {
+		maps.Copy(gCtx.FieldErrors, validationErrs)
+		// A field whose value could not be converted is reported as such,
+		// rather than by validation of the zero value left in its place.
+		for _, err := range bindErrs {
+			gCtx.FieldErrors[err.Path] = err.Message()
+		}
+	}
+
+	switch {
+	case len(bindErrs) > 0 && len(validationErrs) > 0:
+		return errors.Join(bindErrs, validationErrs)
+	case len(bindErrs) > 0:
+		return bindErrs
+	case len(validationErrs) > 0:
+		return validationErrs
+	default:
+		return nil
+	}
 }
 
 // FormValue retrieves the first value for the given form key from the current request.
@@ -51,14 +110,23 @@ func LoaderData[Data any](ctx context.Context) (data Data) {
 	return getContext(ctx).Component.Loader(ctx).(Data)
 }
 
-// Redirect sends a redirect response to the client with the specified path.
-// Uses HTTP status code 303 (See Other) for the redirect.
-// Returns an error if the redirect fails.
-func Redirect(ctx context.Context, path string) error {
-	gCtx := getContext(ctx)
-	gCtx.Writer.Reset()
-	http.Redirect(gCtx.Writer, gCtx.Request, path, http.StatusSeeOther)
-	return nil
+// RenderError returns the error being handled by an error component or fallback.
+// It returns nil outside of an error component or fallback.
+func RenderError(ctx context.Context) error {
+	return getContext(ctx).Err
+}
+
+// URL builds the path of the route registered with the given name.
+// Params are alternating wildcard names and values, for example
+// URL(ctx, "user.detail", "name", user.Name) for a route with path "user/{name}/".
+// Returns an error wrapping ErrUnknownRouteName if no route has the name,
+// or an error if a wildcard is missing a value or a value has no wildcard.
+func URL(ctx context.Context, name string, params ...string) (string, error) {
+	node, ok := getContext(ctx).Node.root().names[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownRouteName, name)
+	}
+	return node.buildURL(params...)
 }
 
 // Header returns the HTTP header map that will be sent by the response.
@@ -104,3 +172,29 @@ func ActionHeaders(ctx context.Context, headers ...string) string {
 func LinkHeaders(ctx context.Context, headers ...string) string {
 	return util.BuildHeaders(append(gongHeaders(ctx, GongRequestTypeLink), headers...))
 }
+
+func isMultipartRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+type hiddenField struct {
+	name  string
+	value string
+}
+
+// hiddenFields returns the form values of v in the order of their names,
+// so that HiddenFields renders the same inputs for the same value.
+func hiddenFields(v any) ([]hiddenField, error) {
+	values, err := bind.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields []hiddenField
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		for _, value := range values[name] {
+			fields = append(fields, hiddenField{name: name, value: value})
+		}
+	}
+	return fields, nil
+}
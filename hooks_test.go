@@ -0,0 +1,263 @@
+package gong
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"html"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/troygilman/gong/internal/assert"
+)
+
+type testUploadForm struct {
+	Title       string                  `form:"title"`
+	Avatar      *multipart.FileHeader   `form:"avatar"`
+	Attachments []*multipart.FileHeader `form:"attachments"`
+}
+
+func TestBind_multipart(t *testing.T) {
+	svr := NewServer(WithMultipartMemory(1024))
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			var form testUploadForm
+			if err := Bind(ctx, &form); err != nil {
+				return err
+			}
+			file, err := form.Avatar.Open()
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			content, err := io.ReadAll(file)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(w, form.Title+":"+form.Avatar.Filename+":"+string(content)+":"+form.Attachments[1].Filename)
+			return err
+		}),
+	}, WithComponentID("mock"))))
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	assert.NoErr(t, mw.WriteField("title", "profile"))
+	for name, filename := range map[string]string{"avatar": "me.png", "attachments": "a.txt"} {
+		part, err := mw.CreateFormFile(name, filename)
+		assert.NoErr(t, err)
+		_, err = io.WriteString(part, "data-"+filename)
+		assert.NoErr(t, err)
+	}
+	part, err := mw.CreateFormFile("attachments", "b.txt")
+	assert.NoErr(t, err)
+	_, err = io.WriteString(part, "b")
+	assert.NoErr(t, err)
+	assert.NoErr(t, mw.Close())
+
+	r := newTestActionRequest(routeSegment("/"), "mock")
+	r.Body = io.NopCloser(&body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, "profile:me.png:data-me.png:b.txt", rec.Body.String())
+}
+
+func TestFormUploadProgress(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: Form(WithUploadProgress("#progress")),
+	})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	body := rec.Body.String()
+	assert.Equals(t, true, strings.Contains(body, `hx-encoding="multipart/form-data"`))
+	assert.Equals(t, true, strings.Contains(body, `hx-on::xhr:progress="htmx.find(&#34;#progress&#34;).setAttribute(&#39;value&#39;, event.detail.loaded / event.detail.total * 100)"`))
+}
+
+type testHiddenForm struct {
+	Name   string         `form:"name"`
+	Note   string         `form:"note"`
+	Tags   []string       `form:"tags"`
+	Scores map[string]int `form:"scores"`
+	Done   bool           `form:"done"`
+}
+
+func TestHiddenFields(t *testing.T) {
+	want := testHiddenForm{
+		Name:   "gong",
+		Note:   `<a href="x"> & 'y'`,
+		Tags:   []string{"", "b"},
+		Scores: map[string]int{"a": 1, "b": 0},
+	}
+	var got testHiddenForm
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: HiddenFields(want),
+		action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			return Bind(ctx, &got)
+		}),
+	}, WithComponentID("mock"))))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+	assert.Equals(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Equals(t, true, strings.Index(body, `name="done"`) < strings.Index(body, `name="name"`))
+
+	form := url.Values{}
+	for _, match := range regexp.MustCompile(`<input type="hidden" name="([^"]*)" value="([^"]*)">`).FindAllStringSubmatch(body, -1) {
+		form.Add(html.UnescapeString(match[1]), html.UnescapeString(match[2]))
+	}
+	assert.Equals(t, 7, len(form))
+
+	r := newTestActionRequest(routeSegment("/"), "mock")
+	r.Body = io.NopCloser(strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec = httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, want, got)
+}
+
+func TestHiddenFields_unencodable(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: HiddenFields(map[string]string{"a[b]": "x"}),
+	})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+	assert.Equals(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestBind_limits(t *testing.T) {
+	var bindErr error
+	svr := NewServer(WithBindLimits(BindLimits{MaxSliceIndex: 10}))
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			var form struct {
+				People []struct {
+					Email string `form:"email"`
+				} `form:"people"`
+			}
+			bindErr = Bind(ctx, &form)
+			return bindErr
+		}),
+	}, WithComponentID("mock"))))
+
+	r := newTestActionRequest(routeSegment("/"), "mock")
+	r.Body = io.NopCloser(strings.NewReader("people[100000000][email]=x"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	var limitErr *BindLimitError
+	assert.Equals(t, true, errors.As(bindErr, &limitErr))
+	assert.Equals(t, "MaxSliceIndex", limitErr.Limit)
+	assert.Equals(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestBind_fieldErrors(t *testing.T) {
+	var bindErr error
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			var form struct {
+				Name string `form:"name" validate:"required"`
+				Age  int    `form:"age" validate:"min=18"`
+			}
+			bindErr = Bind(ctx, &form)
+			_, err := io.WriteString(w, "name:"+FieldError(ctx, "name")+" age:"+FieldError(ctx, "age"))
+			return err
+		}),
+	}, WithComponentID("mock"))))
+
+	r := newTestActionRequest(routeSegment("/"), "mock")
+	r.Body = io.NopCloser(strings.NewReader("name=&age=abc"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	assert.Equals(t, "name:is required age:must be a whole number", rec.Body.String())
+
+	var bindErrs BindErrors
+	assert.Equals(t, true, errors.As(bindErr, &bindErrs))
+	assert.Equals(t, "age", bindErrs[0].Path)
+	assert.Equals(t, "abc", bindErrs[0].Value)
+	var validationErrs ValidationErrors
+	assert.Equals(t, true, errors.As(bindErr, &validationErrs))
+	assert.Equals(t, http.StatusBadRequest, StatusCode(bindErr))
+}
+
+func TestBind_validationStatus(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			var form struct {
+				Email string `form:"email" validate:"email"`
+			}
+			return Bind(ctx, &form)
+		}),
+	}, WithComponentID("mock"))))
+
+	r := newTestActionRequest(routeSegment("/"), "mock")
+	r.Body = io.NopCloser(strings.NewReader("email=invalid"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	assert.Equals(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestBind_malformedForm(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "urlencoded", contentType: "application/x-www-form-urlencoded", body: "name=%zz"},
+		{name: "multipart without boundary", contentType: "multipart/form-data", body: "name=gong"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var bindErr error
+			svr := NewServer()
+			svr.Route(NewRoute("/", NewComponent(testComponent{
+				action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+					var form struct {
+						Name string `form:"name"`
+					}
+					bindErr = Bind(ctx, &form)
+					return bindErr
+				}),
+			}, WithComponentID("mock"))))
+
+			r := newTestActionRequest(routeSegment("/"), "mock")
+			r.Body = io.NopCloser(strings.NewReader(test.body))
+			r.Header.Set("Content-Type", test.contentType)
+
+			rec := httptest.NewRecorder()
+			svr.ServeHTTP(rec, r)
+
+			assert.Equals(t, true, errors.Is(bindErr, ErrMalformedForm))
+			assert.Equals(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package bind
 import (
 	"encoding"
 	"fmt"
+	"mime/multipart"
 	"net/url"
 	"reflect"
 	"slices"
@@ -13,14 +14,26 @@ import (
 )
 
 var (
-	timeType = reflect.TypeOf(time.Time{})
+	timeType        = reflect.TypeOf(time.Time{})
+	fileHeaderType  = reflect.TypeOf(&multipart.FileHeader{})
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader{})
 )
 
 // Bind binds URL form values to a destination object.
 // The destination must be a pointer to a struct, map, or other supported type.
 // It uses struct field tags with the "form" key to map form values to struct fields.
 // Returns an error if binding fails or if the destination is invalid.
+// Form data exceeding DefaultLimits is rejected with a *LimitError.
 func Bind(source url.Values, dest any) error {
+	return BindMultipart(source, nil, dest, DefaultLimits)
+}
+
+// BindMultipart binds the values and uploaded files of a multipart form to a
+// destination object, like Bind. Files are bound to fields of type
+// *multipart.FileHeader, which receive the first file of their key, and
+// []*multipart.FileHeader, which receive all of them.
+// Form data exceeding the limits is rejected with a *LimitError.
+func BindMultipart(source url.Values, files map[string][]*multipart.FileHeader, dest any, limits Limits) error {
 	val := reflect.ValueOf(dest)
 	if val.Kind() != reflect.Pointer {
 		return fmt.Errorf("destination must be a pointer")
@@ -28,37 +41,107 @@ func Bind(source url.Values, dest any) error {
 	if val.IsNil() {
 		return fmt.Errorf("destination is nil")
 	}
-	node := NewParser(NodeMapPool).Parse(source)
+	node, err := NewParser(NodeMapPool).WithLimits(limits).ParseMultipart(source, files)
 	defer node.Cleanup(NodeMapPool)
-	return node.Bind(val)
+	if err != nil {
+		return err
+	}
+	return node.Bind(val, limits)
 }
 
 // Node represents a parsed form data node in a tree structure.
 // It can contain a leaf value (Val) and/or child nodes organized in a hierarchy.
 // This allows for representing nested form data structures.
 type Node struct {
-	Val      string          // The value of this node, if it's a leaf node
-	Children map[string]Node // Child nodes, for nested structures
+	Val      string                  // The value of this node, if it's a leaf node
+	Values   []string                // All values of this node, if its key was given more than once
+	Files    []*multipart.FileHeader // Files uploaded under this node's key
+	Children map[string]Node         // Child nodes, for nested structures
+}
+
+// values returns all values of the node.
+func (node Node) values() []string {
+	if node.Values != nil {
+		return node.Values
+	}
+	return []string{node.Val}
 }
 
 // Bind recursively binds the node's data to the provided destination value.
 // It handles different types (structs, maps, slices, etc.) appropriately.
-// Returns an error if binding fails due to type incompatibility or invalid data.
-func (node Node) Bind(dest reflect.Value) error {
+// Values that cannot be bound, due to type incompatibility or invalid data,
+// do not stop binding: every failure is collected and returned as Errors.
+// A *LimitError, returned if a slice index or the total number of slice
+// elements exceeds the limits, stops binding immediately.
+func (node Node) Bind(dest reflect.Value, limits Limits) error {
+	b := binder{limits: limits}
+	if err := b.bind(node, dest, ""); err != nil {
+		return err
+	}
+	if len(b.errs) > 0 {
+		slices.SortStableFunc(b.errs, func(a, b *FieldError) int {
+			return strings.Compare(a.Path, b.Path)
+		})
+		return b.errs
+	}
+	return nil
+}
+
+// binder collects the errors of a single call to Node.Bind.
+type binder struct {
+	limits Limits
+	errs   Errors
+	// elements counts the slice elements created so far, for MaxElements.
+	elements int
+}
+
+// fail records that the value at path could not be bound to type t.
+func (b *binder) fail(path string, t reflect.Type, value string, err error) {
+	b.errs = append(b.errs, &FieldError{
+		Path:  path,
+		Type:  t,
+		Value: value,
+		Err:   err,
+	})
+}
+
+// bind binds the node at path to dest. It only returns errors that stop
+// binding; all other failures are recorded with fail.
+func (b *binder) bind(node Node, dest reflect.Value, path string) error {
 	t := dest.Type()
+	switch t {
+	case fileHeaderType:
+		if len(node.Files) > 0 {
+			dest.Set(reflect.ValueOf(node.Files[0]))
+		}
+		return nil
+	case fileHeadersType:
+		if len(node.Files) > 0 {
+			dest.Set(reflect.ValueOf(node.Files))
+		}
+		return nil
+	}
+	if tu, ok := textUnmarshaler(dest); ok && len(node.Children) == 0 && !t.ConvertibleTo(timeType) {
+		if node.Val != "" {
+			if err := tu.UnmarshalText([]byte(node.Val)); err != nil {
+				b.fail(path, t, node.Val, err)
+			}
+		}
+		return nil
+	}
 	switch dest.Kind() {
 	case reflect.Pointer:
 		if dest.IsNil() {
 			dest.Set(reflect.New(t.Elem()))
 		}
-		return node.Bind(dest.Elem())
+		return b.bind(node, dest.Elem(), path)
 	case reflect.Interface:
 		if dest.IsNil() {
 			// For interface{}, create a map[string]any
 			m := make(map[string]any, len(node.Children))
 			dest.Set(reflect.ValueOf(m))
 		}
-		return node.Bind(dest.Elem())
+		return b.bind(node, dest.Elem(), path)
 	case reflect.Map:
 		if dest.IsNil() {
 			dest.Set(reflect.MakeMapWithSize(t, len(node.Children)))
@@ -67,27 +150,29 @@ func (node Node) Bind(dest reflect.Value) error {
 		valueType := t.Elem()
 
 		for key, child := range node.Children {
+			childPath := joinPath(path, key)
+
 			// Create new key value
 			keyValue := reflect.New(keyType).Elem()
 			if err := setValueFromString(keyValue, key); err != nil {
-				return err
+				b.fail(childPath, keyType, key, err)
+				continue
 			}
 
 			// Create new value
 			value := reflect.New(valueType).Elem()
 
 			// If this is a leaf node (no children), set the value directly
-			if len(child.Children) == 0 && child.Val != "" {
+			if len(child.Children) == 0 && child.Val != "" && valueType.Kind() != reflect.Slice && valueType.Kind() != reflect.Array {
 				if valueType.Kind() == reflect.Interface {
 					value.Set(reflect.ValueOf(child.Val))
-				} else {
-					if err := setValueFromString(value, child.Val); err != nil {
-						return err
-					}
+				} else if err := setValueFromString(value, child.Val); err != nil {
+					b.fail(childPath, valueType, child.Val, err)
+					continue
 				}
 			} else {
 				// Otherwise, recursively bind the child node
-				if err := child.Bind(value); err != nil {
+				if err := b.bind(child, value, childPath); err != nil {
 					return err
 				}
 			}
@@ -100,7 +185,8 @@ func (node Node) Bind(dest reflect.Value) error {
 			if node.Val != "" {
 				tm, err := time.Parse(time.RFC3339, node.Val)
 				if err != nil {
-					return err
+					b.fail(path, t, node.Val, err)
+					return nil
 				}
 				dest.Set(reflect.ValueOf(tm))
 			}
@@ -115,14 +201,42 @@ func (node Node) Bind(dest reflect.Value) error {
 			if sourceName, ok := tag.Lookup("form"); ok {
 				child, ok := node.Children[sourceName]
 				if !ok {
+					// Unchecked checkboxes are not submitted, so a missing bool is false.
+					if field.Kind() == reflect.Bool {
+						field.SetBool(false)
+					}
 					continue
 				}
-				if err := child.Bind(field); err != nil {
+				if err := b.bind(child, field, joinPath(path, sourceName)); err != nil {
 					return err
 				}
 			}
 		}
 	case reflect.Slice:
+		if len(node.Children) == 0 {
+			// Repeated keys such as "tags=a&tags=b" replace the elements of
+			// the slice in order. Empty values, such as from a hidden input
+			// that lets the list be cleared when no checkbox is checked, are
+			// skipped, so an empty value alone clears the slice.
+			values := slices.DeleteFunc(slices.Clone(node.values()), func(val string) bool {
+				return val == ""
+			})
+			if len(values) == 0 {
+				dest.Set(reflect.Zero(t))
+				return nil
+			}
+			if err := b.addElements(len(values), path); err != nil {
+				return err
+			}
+			elems := reflect.MakeSlice(t, len(values), len(values))
+			for i, val := range values {
+				if err := b.bind(Node{Val: val}, elems.Index(i), path); err != nil {
+					return err
+				}
+			}
+			dest.Set(elems)
+			return nil
+		}
 		if dest.IsNil() {
 			dest.Set(reflect.MakeSlice(t, 0, len(node.Children)))
 		}
@@ -130,28 +244,111 @@ func (node Node) Bind(dest reflect.Value) error {
 			dest.Grow(len(node.Children) - dest.Len())
 		}
 		for key, child := range node.Children {
-			index, err := strconv.Atoi(key)
+			childPath := joinPath(path, key)
+			index, err := b.sliceIndex(key, childPath, t)
 			if err != nil {
 				return err
 			}
+			if index < 0 {
+				continue
+			}
 			if index >= dest.Len() {
+				if err := b.addElements(index-dest.Len()+1, childPath); err != nil {
+					return err
+				}
 				dest.Grow(index - dest.Len() + 1)
 				dest.SetLen(index + 1)
 			}
-			if err := child.Bind(dest.Index(index)); err != nil {
+			if err := b.bind(child, dest.Index(index), childPath); err != nil {
+				return err
+			}
+		}
+	case reflect.Array:
+		if len(node.Children) == 0 {
+			values := node.values()
+			if len(values) > dest.Len() {
+				b.fail(path, t, strings.Join(values, ","), fmt.Errorf("%d values do not fit in an array of length %d", len(values), dest.Len()))
+				return nil
+			}
+			for i, val := range values {
+				if err := b.bind(Node{Val: val}, dest.Index(i), path); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for key, child := range node.Children {
+			childPath := joinPath(path, key)
+			index, err := b.sliceIndex(key, childPath, t)
+			if err != nil {
+				return err
+			}
+			if index < 0 {
+				continue
+			}
+			if index >= dest.Len() {
+				b.fail(childPath, t, key, fmt.Errorf("index %d out of range for an array of length %d", index, dest.Len()))
+				continue
+			}
+			if err := b.bind(child, dest.Index(index), childPath); err != nil {
 				return err
 			}
 		}
 	default:
 		if dest.CanSet() {
-			if err := setValueFromString(dest, node.Val); err != nil {
-				return err
+			val := node.Val
+			// With a hidden input before a checkbox of the same name, the
+			// checkbox's value comes last and wins if it is checked.
+			if dest.Kind() == reflect.Bool {
+				values := node.values()
+				val = values[len(values)-1]
+			}
+			if err := setValueFromString(dest, val); err != nil {
+				b.fail(path, t, val, err)
 			}
 		}
 	}
 	return nil
 }
 
+// joinPath returns the bracket path of the child with the given key,
+// such as "people[2]" for the key "2" below "people".
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "[" + key + "]"
+}
+
+// sliceIndex parses the key of a slice or array element, such as "2" in "people[2]".
+// Invalid keys are recorded as failures and reported with a negative index,
+// while indexes beyond the limits stop binding with a *LimitError.
+func (b *binder) sliceIndex(key string, path string, t reflect.Type) (int, error) {
+	index, err := strconv.Atoi(key)
+	if err != nil {
+		b.fail(path, t, key, fmt.Errorf("invalid index %q", key))
+		return -1, nil
+	}
+	if index < 0 {
+		b.fail(path, t, key, fmt.Errorf("negative index %d", index))
+		return -1, nil
+	}
+	if b.limits.MaxSliceIndex > 0 && index > b.limits.MaxSliceIndex {
+		return -1, &LimitError{Limit: "MaxSliceIndex", Max: b.limits.MaxSliceIndex, Key: path}
+	}
+	return index, nil
+}
+
+// addElements counts n new slice elements, created for the key at path,
+// against the MaxElements limit, which is shared by the whole form.
+func (b *binder) addElements(n int, path string) error {
+	b.elements += n
+	if b.limits.MaxElements > 0 && b.elements > b.limits.MaxElements {
+		return &LimitError{Limit: "MaxElements", Max: b.limits.MaxElements, Key: path}
+	}
+	return nil
+}
+
 // Cleanup frees resources used by the node, returning them to the provided pool.
 // This helps reduce memory allocations by recycling node maps.
 func (node Node) Cleanup(pool *sync.Pool) {
@@ -174,7 +371,9 @@ func (node Node) stringWithIndent(level int) string {
 	var b strings.Builder
 	indent := strings.Repeat(" ", level)
 
-	if node.Val != "" {
+	if node.Values != nil {
+		b.WriteString(strings.Join(node.Values, ", "))
+	} else if node.Val != "" {
 		b.WriteString(node.Val)
 	}
 
@@ -209,7 +408,20 @@ func (node Node) stringWithIndent(level int) string {
 	return b.String()
 }
 
+// textUnmarshaler returns dest as an encoding.TextUnmarshaler if its pointer
+// implements it. Pointers and interfaces are left to be dereferenced first.
+func textUnmarshaler(dest reflect.Value) (encoding.TextUnmarshaler, bool) {
+	if dest.Kind() == reflect.Pointer || dest.Kind() == reflect.Interface || !dest.CanAddr() {
+		return nil, false
+	}
+	tu, ok := dest.Addr().Interface().(encoding.TextUnmarshaler)
+	return tu, ok
+}
+
 func setValueFromString(dest reflect.Value, str string) error {
+	if tu, ok := textUnmarshaler(dest); ok {
+		return tu.UnmarshalText([]byte(str))
+	}
 	switch dest.Kind() {
 	case reflect.String:
 		dest.SetString(str)
@@ -232,17 +444,16 @@ func setValueFromString(dest reflect.Value, str string) error {
 		}
 		dest.SetFloat(val)
 	case reflect.Bool:
+		// Checked checkboxes without a value attribute submit "on".
+		if str == "on" {
+			dest.SetBool(true)
+			return nil
+		}
 		val, err := strconv.ParseBool(str)
 		if err != nil {
 			return err
 		}
 		dest.SetBool(val)
-	default:
-		if dest.CanAddr() {
-			if tu, ok := dest.Addr().Interface().(encoding.TextUnmarshaler); ok {
-				return tu.UnmarshalText([]byte(str))
-			}
-		}
 	}
 	return nil
 }
@@ -1,8 +1,11 @@
 package bind
 
 import (
+	"errors"
+	"mime/multipart"
 	"net/url"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -239,6 +242,131 @@ func TestBindStruct(t *testing.T) {
 	}
 }
 
+type MultiValue struct {
+	Tags      []string            `form:"tags"`
+	IDs       []int               `form:"ids"`
+	Pair      [2]string           `form:"pair"`
+	Active    bool                `form:"active"`
+	Agree     bool                `form:"agree"`
+	Subscribe bool                `form:"subscribe"`
+	Options   map[string][]string `form:"options"`
+}
+
+func TestBindMultiValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  url.Values
+		dest    *MultiValue
+		want    MultiValue
+		wantErr bool
+	}{
+		{
+			name: "repeated keys",
+			values: url.Values{
+				"tags": {"a", "b"},
+				"ids":  {"1", "2", "3"},
+			},
+			dest: new(MultiValue),
+			want: MultiValue{Tags: []string{"a", "b"}, IDs: []int{1, 2, 3}},
+		},
+		{
+			name: "bracket suffix",
+			values: url.Values{
+				"tags[]": {"a", "b"},
+			},
+			dest: new(MultiValue),
+			want: MultiValue{Tags: []string{"a", "b"}},
+		},
+		{
+			name: "single value",
+			values: url.Values{
+				"tags": {"a"},
+			},
+			dest: new(MultiValue),
+			want: MultiValue{Tags: []string{"a"}},
+		},
+		{
+			name: "empty value",
+			values: url.Values{
+				"tags": {""},
+			},
+			dest: new(MultiValue),
+			want: MultiValue{},
+		},
+		{
+			name: "hidden empty value before checkboxes",
+			values: url.Values{
+				"tags": {"", "a"},
+				"ids":  {"", "1", "2"},
+			},
+			dest: new(MultiValue),
+			want: MultiValue{Tags: []string{"a"}, IDs: []int{1, 2}},
+		},
+		{
+			name: "repeated values replace",
+			values: url.Values{
+				"tags": {"a", "b"},
+			},
+			dest: &MultiValue{Tags: []string{"old"}},
+			want: MultiValue{Tags: []string{"a", "b"}},
+		},
+		{
+			name: "empty value clears",
+			values: url.Values{
+				"tags": {""},
+			},
+			dest: &MultiValue{Tags: []string{"a", "b"}},
+			want: MultiValue{},
+		},
+		{
+			name: "array",
+			values: url.Values{
+				"pair": {"x", "y"},
+			},
+			dest: new(MultiValue),
+			want: MultiValue{Pair: [2]string{"x", "y"}},
+		},
+		{
+			name: "array overflow",
+			values: url.Values{
+				"pair": {"x", "y", "z"},
+			},
+			dest:    new(MultiValue),
+			wantErr: true,
+		},
+		{
+			name: "checkboxes",
+			values: url.Values{
+				"active": {"on"},
+				"agree":  {"false", "on"},
+			},
+			dest: &MultiValue{Subscribe: true},
+			want: MultiValue{Active: true, Agree: true},
+		},
+		{
+			name: "nested repeated keys",
+			values: url.Values{
+				"options[colors]":  {"red", "blue"},
+				"options[sizes][]": {"s"},
+			},
+			dest: new(MultiValue),
+			want: MultiValue{Options: map[string][]string{"colors": {"red", "blue"}, "sizes": {"s"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Bind(tt.values, tt.dest)
+			if tt.wantErr {
+				assert.Err(t, err)
+				return
+			}
+			assert.NoErr(t, err)
+			assert.Equals(t, tt.want, *tt.dest)
+		})
+	}
+}
+
 func TestBindMap(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -534,3 +662,139 @@ func BenchmarkBindComplex(b *testing.B) {
 		}
 	}
 }
+
+func TestBindMultipart(t *testing.T) {
+	type Upload struct {
+		Title       string                  `form:"title"`
+		Avatar      *multipart.FileHeader   `form:"avatar"`
+		Attachments []*multipart.FileHeader `form:"attachments"`
+		Nested      struct {
+			Photo *multipart.FileHeader `form:"photo"`
+		} `form:"nested"`
+	}
+
+	avatar := &multipart.FileHeader{Filename: "avatar.png"}
+	a := &multipart.FileHeader{Filename: "a.txt"}
+	b := &multipart.FileHeader{Filename: "b.txt"}
+	photo := &multipart.FileHeader{Filename: "photo.jpg"}
+
+	var upload Upload
+	err := BindMultipart(url.Values{"title": {"hello"}}, map[string][]*multipart.FileHeader{
+		"avatar":        {avatar},
+		"attachments[]": {a, b},
+		"nested[photo]": {photo},
+	}, &upload, DefaultLimits)
+
+	assert.NoErr(t, err)
+	assert.Equals(t, "hello", upload.Title)
+	assert.Equals(t, avatar, upload.Avatar)
+	assert.Equals(t, []*multipart.FileHeader{a, b}, upload.Attachments)
+	assert.Equals(t, photo, upload.Nested.Photo)
+}
+
+func TestBindLimits(t *testing.T) {
+	limits := Limits{MaxSliceIndex: 10, MaxDepth: 3, MaxKeys: 4, MaxFields: 5}
+
+	tests := []struct {
+		name   string
+		values url.Values
+		limit  string
+	}{
+		{
+			name:   "slice index",
+			values: url.Values{"people[100000000][email]": {"x"}},
+			limit:  "MaxSliceIndex",
+		},
+		{
+			name:   "depth",
+			values: url.Values{"a[b][c][d][e]": {"x"}},
+			limit:  "MaxDepth",
+		},
+		{
+			name:   "keys",
+			values: url.Values{"a": {"1"}, "b": {"2"}, "c": {"3"}, "d": {"4"}, "e": {"5"}},
+			limit:  "MaxKeys",
+		},
+		{
+			name:   "fields",
+			values: url.Values{"tags": {"1", "2", "3", "4", "5", "6"}},
+			limit:  "MaxFields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var dest ComplexStruct
+			err := BindMultipart(tt.values, nil, &dest, limits)
+			var limitErr *LimitError
+			assert.Equals(t, true, errors.As(err, &limitErr))
+			assert.Equals(t, tt.limit, limitErr.Limit)
+		})
+	}
+}
+
+func TestBindMaxElements(t *testing.T) {
+	limits := Limits{MaxSliceIndex: 1000, MaxElements: 10000}
+	values := url.Values{}
+	for i := range 20 {
+		values.Set("groups["+strconv.Itoa(i)+"][items][1000]", "x")
+	}
+
+	var dest struct {
+		Groups []struct {
+			Items []string `form:"items"`
+		} `form:"groups"`
+	}
+	err := BindMultipart(values, nil, &dest, limits)
+	var limitErr *LimitError
+	assert.Equals(t, true, errors.As(err, &limitErr))
+	assert.Equals(t, "MaxElements", limitErr.Limit)
+
+	limits.MaxElements = 0
+	assert.NoErr(t, BindMultipart(values, nil, &dest, limits))
+	assert.Equals(t, 20, len(dest.Groups))
+}
+
+func TestBindNegativeIndex(t *testing.T) {
+	var dest ComplexStruct
+	err := Bind(url.Values{"people[-1][email]": {"x"}}, &dest)
+	assert.Err(t, err)
+}
+
+func TestBindFieldErrors(t *testing.T) {
+	var dest ComplexStruct
+	err := Bind(url.Values{
+		"people[0][first_name]": {"Ann"},
+		"people[2][age]":        {"abc"},
+		"people[x][age]":        {"1"},
+		"counts[a]":             {"many"},
+		"created_at":            {"yesterday"},
+		"nested[role]":          {"admin"},
+	}, &dest)
+
+	var errs Errors
+	assert.Equals(t, true, errors.As(err, &errs))
+	assert.Equals(t, 4, len(errs))
+
+	assert.Equals(t, "counts[a]", errs[0].Path)
+	assert.Equals(t, reflect.TypeOf(0), errs[0].Type)
+	assert.Equals(t, "many", errs[0].Value)
+	assert.Equals(t, "must be a whole number", errs[0].Message())
+
+	assert.Equals(t, "created_at", errs[1].Path)
+	assert.Equals(t, reflect.TypeOf(time.Time{}), errs[1].Type)
+	assert.Equals(t, "yesterday", errs[1].Value)
+
+	assert.Equals(t, "people[2][age]", errs[2].Path)
+	assert.Equals(t, reflect.TypeOf(0), errs[2].Type)
+	assert.Equals(t, "abc", errs[2].Value)
+	var numErr *strconv.NumError
+	assert.Equals(t, true, errors.As(errs[2], &numErr))
+
+	assert.Equals(t, "people[x]", errs[3].Path)
+	assert.Equals(t, reflect.TypeOf([]Person{}), errs[3].Type)
+
+	// Valid fields are still bound.
+	assert.Equals(t, "Ann", dest.People[0].FirstName)
+	assert.Equals(t, "admin", dest.Nested.Role)
+}
@@ -0,0 +1,192 @@
+package bind
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// Encode is the inverse of Bind: it converts v into URL form values that Bind
+// decodes back into an equal value. Struct fields are encoded by their "form"
+// tags, and nested structs, slices, arrays, and maps use bracket notation,
+// such as "people[0][email]". Slices and arrays of strings, numbers, bools,
+// times, and text marshalers are encoded as a repeated key, such as
+// "tags=a&tags=b", so their length is not bounded by the MaxKeys and
+// MaxSliceIndex limits, unless a slice has empty elements, which Bind only
+// keeps when they are indexed. Times are encoded as RFC 3339, and types that
+// implement encoding.TextMarshaler with their text. Nil pointers and nil
+// interfaces are left out.
+// Returns an error if v holds a value that Bind cannot decode back, such as an
+// uploaded file, a map key that is empty or contains brackets, or a value
+// whose MarshalText fails.
+func Encode(v any) (url.Values, error) {
+	values := url.Values{}
+	if err := encodeValue(values, "", reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func encodeValue(values url.Values, path string, val reflect.Value) error {
+	if !val.IsValid() {
+		return nil
+	}
+	switch val.Type() {
+	case fileHeaderType, fileHeadersType:
+		if val.IsNil() || (val.Kind() == reflect.Slice && val.Len() == 0) {
+			return nil
+		}
+		return fmt.Errorf("bind: cannot encode %q: uploaded files cannot be encoded", path)
+	}
+	if isLeafType(val.Type()) {
+		text, err := encodeLeaf(val)
+		if err != nil {
+			return fmt.Errorf("bind: cannot encode %q: %w", path, err)
+		}
+		if path == "" {
+			return fmt.Errorf("bind: cannot encode %s without a form key", val.Type())
+		}
+		values.Add(path, text)
+		return nil
+	}
+	switch val.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !val.IsNil() {
+			return encodeValue(values, path, val.Elem())
+		}
+	case reflect.Struct:
+		t := val.Type()
+		for index := range val.NumField() {
+			field := val.Field(index)
+			if !field.CanInterface() {
+				continue
+			}
+			if name, ok := t.Field(index).Tag.Lookup("form"); ok {
+				if err := encodeValue(values, joinPath(path, name), field); err != nil {
+					return err
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if path != "" && isLeafType(val.Type().Elem()) {
+			if val.Len() == 0 {
+				return nil
+			}
+			texts := make([]string, val.Len())
+			for i := range val.Len() {
+				text, err := encodeLeaf(val.Index(i))
+				if err != nil {
+					return fmt.Errorf("bind: cannot encode %q: %w", joinPath(path, strconv.Itoa(i)), err)
+				}
+				texts[i] = text
+			}
+			// Bind skips the empty values of a repeated key for slices, so
+			// slices with empty elements are indexed instead.
+			if val.Kind() == reflect.Slice && slices.Contains(texts, "") {
+				for i, text := range texts {
+					values.Add(joinPath(path, strconv.Itoa(i)), text)
+				}
+				return nil
+			}
+			values[path] = append(values[path], texts...)
+			return nil
+		}
+		for i := range val.Len() {
+			if err := encodeValue(values, joinPath(path, strconv.Itoa(i)), val.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if !isLeafType(val.Type().Key()) {
+			return fmt.Errorf("bind: cannot encode %q: unsupported map key type %s", path, val.Type().Key())
+		}
+		iter := val.MapRange()
+		for iter.Next() {
+			key, err := encodeLeaf(iter.Key())
+			if err != nil {
+				return fmt.Errorf("bind: cannot encode %q: %w", path, err)
+			}
+			if key == "" || strings.ContainsAny(key, "[]") {
+				return fmt.Errorf("bind: cannot encode %q: map key %q is empty or contains brackets", path, key)
+			}
+			if err := encodeValue(values, joinPath(path, key), iter.Value()); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("bind: cannot encode %q: unsupported type %s", path, val.Type())
+	}
+	return nil
+}
+
+// isLeafType reports whether values of type t are encoded as a single string:
+// text marshalers, times, strings, numbers, and bools.
+func isLeafType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface {
+		return false
+	}
+	if t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		return t.ConvertibleTo(timeType)
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// encodeLeaf formats a value of a leaf type as setValueFromString parses it.
+func encodeLeaf(val reflect.Value) (string, error) {
+	if marshaler, ok := textMarshaler(val); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(text), nil
+	}
+	switch val.Kind() {
+	case reflect.Struct:
+		return val.Convert(timeType).Interface().(time.Time).Format(time.RFC3339Nano), nil
+	case reflect.String:
+		return val.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'g', -1, val.Type().Bits()), nil
+	default:
+		return strconv.FormatBool(val.Bool()), nil
+	}
+}
+
+// textMarshaler returns val as an encoding.TextMarshaler if its type, or its
+// pointer type, implements it.
+func textMarshaler(val reflect.Value) (encoding.TextMarshaler, bool) {
+	if val.CanAddr() {
+		val = val.Addr()
+	} else if !val.Type().Implements(textMarshalerType) {
+		// Copy the value so that pointer-receiver methods can be called.
+		ptr := reflect.New(val.Type())
+		ptr.Elem().Set(val)
+		val = ptr
+	}
+	if !val.CanInterface() {
+		return nil, false
+	}
+	marshaler, ok := val.Interface().(encoding.TextMarshaler)
+	return marshaler, ok
+}
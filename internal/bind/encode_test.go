@@ -0,0 +1,201 @@
+package bind
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/troygilman/gong/internal/assert"
+)
+
+type level int
+
+func (l level) MarshalText() ([]byte, error) {
+	switch l {
+	case 0:
+		return []byte("low"), nil
+	case 1:
+		return []byte("high"), nil
+	}
+	return nil, fmt.Errorf("invalid level %d", int(l))
+}
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 0
+	case "high":
+		*l = 1
+	default:
+		return fmt.Errorf("invalid level %q", text)
+	}
+	return nil
+}
+
+type Encoded struct {
+	Name     string           `form:"name"`
+	Count    *int             `form:"count"`
+	Missing  *int             `form:"missing"`
+	Ratio    float32          `form:"ratio"`
+	Active   bool             `form:"active"`
+	Level    level            `form:"level"`
+	Levels   map[level]string `form:"levels"`
+	Tags     []string         `form:"tags"`
+	Pair     [2]int           `form:"pair"`
+	Grid     [][]int          `form:"grid"`
+	Person   *Person          `form:"person"`
+	Seen     time.Time        `form:"seen"`
+	internal string
+	Untagged string
+}
+
+func TestEncode(t *testing.T) {
+	count := 3
+	values, err := Encode(&Encoded{
+		Name:     "gong",
+		Count:    &count,
+		Ratio:    0.1,
+		Level:    1,
+		Levels:   map[level]string{0: "quiet"},
+		Tags:     []string{"a", ""},
+		Pair:     [2]int{4, 5},
+		Grid:     [][]int{{1}, {2, 3}},
+		Person:   &Person{FirstName: "Ann"},
+		Seen:     time.Date(2024, 3, 20, 15, 4, 5, 6, time.UTC),
+		internal: "x",
+		Untagged: "y",
+	})
+	assert.NoErr(t, err)
+
+	assert.Equals(t, url.Values{
+		"name":               {"gong"},
+		"count":              {"3"},
+		"ratio":              {"0.1"},
+		"active":             {"false"},
+		"level":              {"high"},
+		"levels[low]":        {"quiet"},
+		"tags[0]":            {"a"},
+		"tags[1]":            {""},
+		"pair":               {"4", "5"},
+		"grid[0]":            {"1"},
+		"grid[1]":            {"2", "3"},
+		"person[first_name]": {"Ann"},
+		"person[last_name]":  {""},
+		"person[email]":      {""},
+		"person[age]":        {"0"},
+		"person[active]":     {"false"},
+		"person[score]":      {"0"},
+		"seen":               {"2024-03-20T15:04:05.000000006Z"},
+	}, values)
+}
+
+func TestEncodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  any
+	}{
+		{name: "bracket map key", src: map[string]string{"a": "1", "b[c]": "2"}},
+		{name: "empty map key", src: map[string]string{"": "1"}},
+		{name: "struct map key", src: map[Person]string{{}: "1"}},
+		{name: "marshal error", src: &Encoded{Level: 2}},
+		{name: "marshal error in slice", src: map[string][]level{"levels": {0, 2}}},
+		{name: "unsupported type", src: map[string]any{"done": make(chan int)}},
+		{name: "file", src: map[string]*multipart.FileHeader{"avatar": {Filename: "a.png"}}},
+		{name: "no key", src: "gong"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Encode(tt.src)
+			assert.Err(t, err)
+		})
+	}
+}
+
+func TestEncodeScalarSlices(t *testing.T) {
+	ids := make([]int, 2000)
+	for i := range ids {
+		ids[i] = i
+	}
+	values, err := Encode(&MultiValue{IDs: ids, Tags: []string{""}})
+	assert.NoErr(t, err)
+	assert.Equals(t, 2000, len(values["ids"]))
+	assert.Equals(t, []string{""}, values["tags[0]"])
+
+	var dest MultiValue
+	assert.NoErr(t, Bind(values, &dest))
+	assert.Equals(t, ids, dest.IDs)
+	assert.Equals(t, []string{""}, dest.Tags)
+
+	values, err = Encode(&MultiValue{Tags: []string{}})
+	assert.NoErr(t, err)
+	_, ok := values["tags"]
+	assert.Equals(t, false, ok)
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	count := 0
+	tests := []struct {
+		name string
+		src  any
+		dest func() any
+	}{
+		{
+			name: "complex",
+			src: &ComplexStruct{
+				People: []Person{
+					{FirstName: "John", Email: "john@example.com", Age: 30, Active: true, Score: 4.25},
+					{LastName: "Doe"},
+				},
+				Settings:  map[string]string{"theme": "dark", "empty": ""},
+				Metadata:  map[string]any{"name": "John"},
+				CreatedAt: time.Date(2024, 3, 20, 15, 4, 5, 123, time.UTC),
+				Tags:      []string{"", "go"},
+				Counts:    map[string]int{"users": 100, "zero": 0},
+				Ratings:   map[string]float64{"quality": 0.1},
+				Flags:     map[string]bool{"enabled": true, "disabled": false},
+				IntKeyMap: map[int]string{-1: "minus one"},
+				Nested:    NestedStruct{Person: Person{FirstName: "Jane"}, Role: "admin"},
+			},
+			dest: func() any { return new(ComplexStruct) },
+		},
+		{
+			name: "multi value",
+			src: &MultiValue{
+				Tags:    []string{""},
+				IDs:     []int{1, 2},
+				Pair:    [2]string{"", "y"},
+				Agree:   true,
+				Options: map[string][]string{"colors": {"red", "blue"}},
+			},
+			dest: func() any { return new(MultiValue) },
+		},
+		{
+			name: "encoded",
+			src: &Encoded{
+				Name:   "gong",
+				Count:  &count,
+				Ratio:  0.1,
+				Level:  1,
+				Levels: map[level]string{1: "loud"},
+				Pair:   [2]int{0, 5},
+				Grid:   [][]int{{1}, {2, 3}},
+				Person: &Person{FirstName: "Ann"},
+				Seen:   time.Date(2024, 3, 20, 15, 4, 5, 6, time.UTC),
+			},
+			dest: func() any { return new(Encoded) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := tt.dest()
+			values, err := Encode(tt.src)
+			assert.NoErr(t, err)
+			assert.NoErr(t, Bind(values, dest))
+			assert.Equals(t, tt.src, dest)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package bind
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldError describes a form value that could not be bound.
+type FieldError struct {
+	// Path is the bracket path of the form field, such as "people[2][age]".
+	Path string
+	// Type is the Go type the value was bound to.
+	Type reflect.Type
+	// Value is the raw form value.
+	Value string
+	// Err is the underlying error, such as a *strconv.NumError.
+	Err error
+}
+
+func (err *FieldError) Error() string {
+	return fmt.Sprintf("bind: field %q: cannot bind %q to %s: %v", err.Path, err.Value, err.Type, err.Err)
+}
+
+func (err *FieldError) Unwrap() error {
+	return err.Err
+}
+
+// Message returns a description of the failure that can be shown next to the
+// form field, completing a sentence about the field.
+func (err *FieldError) Message() string {
+	if err.Type.ConvertibleTo(timeType) {
+		return "must be a valid date and time"
+	}
+	switch err.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "must be a whole number"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "must be a positive whole number"
+	case reflect.Float32, reflect.Float64:
+		return "must be a number"
+	case reflect.Bool:
+		return "must be true or false"
+	default:
+		return "is invalid"
+	}
+}
+
+// Errors collects every FieldError of a call to Bind.
+type Errors []*FieldError
+
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the field errors, so errors.Is and errors.As can match them.
+func (errs Errors) Unwrap() []error {
+	unwrapped := make([]error, len(errs))
+	for i, err := range errs {
+		unwrapped[i] = err
+	}
+	return unwrapped
+}
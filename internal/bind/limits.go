@@ -0,0 +1,48 @@
+package bind
+
+import "fmt"
+
+// Limits bounds the form data accepted by Bind, so a single request cannot
+// make the server allocate huge slices or recurse without limit.
+// A zero field means that dimension is not limited.
+type Limits struct {
+	// MaxSliceIndex is the largest index accepted in a key such as "people[3]".
+	MaxSliceIndex int
+	// MaxDepth is the largest number of bracketed segments in a key,
+	// for example 2 in "people[3][email]".
+	MaxDepth int
+	// MaxKeys is the largest number of distinct keys in the form.
+	MaxKeys int
+	// MaxFields is the largest number of values in the form, counting every
+	// value of a repeated key and every uploaded file.
+	MaxFields int
+	// MaxElements is the largest number of slice elements that binding the
+	// form may create in total, across every slice of the destination.
+	MaxElements int
+}
+
+// DefaultLimits are the limits used by Bind.
+var DefaultLimits = Limits{
+	MaxSliceIndex: 1000,
+	MaxDepth:      32,
+	MaxKeys:       1000,
+	MaxFields:     10000,
+	MaxElements:   10000,
+}
+
+// LimitError is returned when form data exceeds one of the Limits.
+type LimitError struct {
+	// Limit is the name of the exceeded field of Limits, such as "MaxDepth".
+	Limit string
+	// Max is the value of the exceeded limit.
+	Max int
+	// Key is the form key, or key segment, that exceeded the limit, if any.
+	Key string
+}
+
+func (err *LimitError) Error() string {
+	if err.Key == "" {
+		return fmt.Sprintf("bind: form exceeds %s of %d", err.Limit, err.Max)
+	}
+	return fmt.Sprintf("bind: form key %q exceeds %s of %d", err.Key, err.Limit, err.Max)
+}
@@ -1,7 +1,9 @@
 package bind
 
 import (
+	"mime/multipart"
 	"net/url"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -20,46 +22,121 @@ var (
 // It uses a sync.Pool to recycle maps for better performance.
 type Parser struct {
 	nodeMapPool *sync.Pool
+	limits      Limits
 }
 
 // NewParser creates a new Parser with the provided node map pool.
 // The node map pool is used to recycle maps when parsing form data.
+// The parser enforces DefaultLimits unless others are set with WithLimits.
 func NewParser(nodeMapPool *sync.Pool) Parser {
 	return Parser{
 		nodeMapPool: nodeMapPool,
+		limits:      DefaultLimits,
 	}
 }
 
+// WithLimits returns a copy of the parser that enforces the given limits.
+func (parser Parser) WithLimits(limits Limits) Parser {
+	parser.limits = limits
+	return parser
+}
+
 // Parse converts URL form values into a structured Node tree.
 // It handles nested form fields using bracket notation (e.g., "user[name]").
+// Keys with several values, such as repeated keys or keys ending in "[]",
+// keep all of their values in the node's Values.
 // The resulting Node tree can then be bound to Go types using Bind.
-func (parser Parser) Parse(source url.Values) Node {
+// It returns a *LimitError if the form exceeds the parser's limits, along
+// with the partially built tree, which should still be cleaned up.
+func (parser Parser) Parse(source url.Values) (Node, error) {
+	return parser.ParseMultipart(source, nil)
+}
+
+// ParseMultipart is like Parse, but also adds the uploaded files of a
+// multipart form to the nodes of their keys.
+func (parser Parser) ParseMultipart(source url.Values, files map[string][]*multipart.FileHeader) (Node, error) {
 	node := Node{
 		Children: parser.nodeMapPool.Get().(map[string]Node),
 	}
+	if err := parser.checkSize(source, files); err != nil {
+		return node, err
+	}
+	var err error
 	for path, val := range source {
-		start := strings.Index(path, "[")
-		if start == -1 {
-			node.Children[path] = Node{
-				Val: val[0],
-			}
-		} else {
-			key := path[:start]
-			child := node.Children[key]
-			child = parser.populateNode(child, path, start, val)
-			node.Children[key] = child
+		if len(val) == 0 {
+			continue
+		}
+		node, err = parser.insert(node, path, func(leaf Node) Node {
+			return leaf.withValues(val)
+		})
+		if err != nil {
+			return node, err
 		}
 	}
-	return node
+	for path, headers := range files {
+		if len(headers) == 0 {
+			continue
+		}
+		node, err = parser.insert(node, path, func(leaf Node) Node {
+			leaf.Files = append(leaf.Files, headers...)
+			return leaf
+		})
+		if err != nil {
+			return node, err
+		}
+	}
+	return node, nil
+}
+
+// checkSize enforces the MaxKeys and MaxFields limits.
+func (parser Parser) checkSize(source url.Values, files map[string][]*multipart.FileHeader) error {
+	if parser.limits.MaxKeys > 0 && len(source)+len(files) > parser.limits.MaxKeys {
+		return &LimitError{Limit: "MaxKeys", Max: parser.limits.MaxKeys}
+	}
+	if parser.limits.MaxFields > 0 {
+		fields := 0
+		for _, val := range source {
+			fields += len(val)
+		}
+		for _, headers := range files {
+			fields += len(headers)
+		}
+		if fields > parser.limits.MaxFields {
+			return &LimitError{Limit: "MaxFields", Max: parser.limits.MaxFields}
+		}
+	}
+	return nil
+}
+
+// insert updates the node at the form field path with the leaf function.
+func (parser Parser) insert(node Node, path string, leaf func(Node) Node) (Node, error) {
+	// "tags[]" is the conventional name for a list, equivalent to repeating "tags".
+	trimmed := strings.TrimSuffix(path, "[]")
+	start := strings.IndexByte(trimmed, '[')
+	if start == -1 {
+		node.Children[trimmed] = leaf(node.Children[trimmed])
+		return node, nil
+	}
+	key := trimmed[:start]
+	child, err := parser.populateNode(node.Children[key], path, trimmed, start, 1, leaf)
+	node.Children[key] = child
+	return node, err
 }
 
 // populateNode recursively builds a Node tree from a form field path.
-// It parses nested fields using bracket notation and assigns values at leaf nodes.
+// It parses nested fields using bracket notation and applies the leaf function at leaf nodes.
 // For example, "user[name][first]" would create a nested tree of nodes.
-func (parser Parser) populateNode(node Node, path string, start int, val []string) Node {
-	end := start
-	for end < len(path) && path[end] != ']' {
-		end++
+// Text after a closing bracket that does not start another segment is ignored.
+func (parser Parser) populateNode(node Node, fullPath string, path string, start int, depth int, leaf func(Node) Node) (Node, error) {
+	if parser.limits.MaxDepth > 0 && depth > parser.limits.MaxDepth {
+		return node, &LimitError{Limit: "MaxDepth", Max: parser.limits.MaxDepth, Key: fullPath}
+	}
+
+	end := strings.IndexByte(path[start:], ']')
+	if end == -1 {
+		end = len(path)
+	} else {
+		end += start
 	}
 
 	key := path[start+1 : end]
@@ -67,14 +144,25 @@ func (parser Parser) populateNode(node Node, path string, start int, val []strin
 		node.Children = parser.nodeMapPool.Get().(map[string]Node)
 	}
 
-	if end == len(path)-1 {
-		node.Children[key] = Node{
-			Val: val[0],
-		}
-	} else {
-		child := node.Children[key]
-		child = parser.populateNode(child, path, end+1, val)
+	if end+1 < len(path) && path[end+1] == '[' {
+		child, err := parser.populateNode(node.Children[key], fullPath, path, end+1, depth+1, leaf)
 		node.Children[key] = child
+		return node, err
+	}
+	node.Children[key] = leaf(node.Children[key])
+	return node, nil
+}
+
+// withValues adds the values of a form key to the node. Values of keys that
+// map to the same node, such as "tags" and "tags[]", are combined.
+func (node Node) withValues(val []string) Node {
+	if node.Val == "" && node.Values == nil {
+		node.Val = val[0]
+		if len(val) > 1 {
+			node.Values = val
+		}
+		return node
 	}
+	node.Values = slices.Concat(node.values(), val)
 	return node
 }
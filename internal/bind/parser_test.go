@@ -2,6 +2,7 @@ package bind
 
 import (
 	"net/url"
+	"reflect"
 	"testing"
 
 	"github.com/troygilman/gong/internal/assert"
@@ -20,8 +21,9 @@ func TestParser_Parse(t *testing.T) {
 	}
 
 	// Parse the values
-	result := parser.Parse(testValues)
+	result, err := parser.Parse(testValues)
 	defer result.Cleanup(NodeMapPool)
+	assert.NoErr(t, err)
 
 	expected := Node{
 		Val: "",
@@ -53,3 +55,92 @@ func TestParser_Parse(t *testing.T) {
 
 	assert.Equals(t, expected, result)
 }
+
+func TestParser_Parse_repeatedKeys(t *testing.T) {
+	parser := NewParser(NodeMapPool)
+
+	result, err := parser.Parse(url.Values{
+		"tags":          {"a", "b"},
+		"user[roles][]": {"admin", "editor"},
+		"user[name]":    {"John"},
+	})
+	defer result.Cleanup(NodeMapPool)
+	assert.NoErr(t, err)
+
+	expected := Node{
+		Children: map[string]Node{
+			"tags": {
+				Val:    "a",
+				Values: []string{"a", "b"},
+			},
+			"user": {
+				Children: map[string]Node{
+					"roles": {
+						Val:    "admin",
+						Values: []string{"admin", "editor"},
+					},
+					"name": {
+						Val: "John",
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equals(t, expected, result)
+}
+
+func TestParser_Parse_combinedKeys(t *testing.T) {
+	parser := NewParser(NodeMapPool)
+
+	result, err := parser.Parse(url.Values{
+		"tags":   {"a"},
+		"tags[]": {"b"},
+	})
+	defer result.Cleanup(NodeMapPool)
+	assert.NoErr(t, err)
+
+	assert.Equals(t, 2, len(result.Children["tags"].Values))
+}
+
+func TestParser_Parse_malformedKeys(t *testing.T) {
+	parser := NewParser(NodeMapPool)
+
+	result, err := parser.Parse(url.Values{
+		"a[":     {"1"},
+		"b[c]d":  {"2"},
+		"e[f]][": {"3"},
+		"[g]":    {"4"},
+	})
+	defer result.Cleanup(NodeMapPool)
+
+	assert.NoErr(t, err)
+	assert.Equals(t, "1", result.Children["a"].Children[""].Val)
+	assert.Equals(t, "2", result.Children["b"].Children["c"].Val)
+	assert.Equals(t, "3", result.Children["e"].Children["f"].Val)
+	assert.Equals(t, "4", result.Children[""].Children["g"].Val)
+}
+
+func FuzzParser(f *testing.F) {
+	f.Add("user[name]=John&user[address][city]=New+York&simple=value")
+	f.Add("people[0][email]=a&people[1][email]=b&tags[]=x&tags[]=y")
+	f.Add("a[b][c][d][e][f][g][h]=deep")
+	f.Add("people[100000000][email]=x")
+	f.Add("a[=1&b[c]d=2&[e]=3&f]]=4")
+
+	f.Fuzz(func(t *testing.T, query string) {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return
+		}
+		node, err := NewParser(NodeMapPool).Parse(values)
+		defer node.Cleanup(NodeMapPool)
+		if err != nil {
+			return
+		}
+		var dest ComplexStruct
+		_ = node.Bind(reflect.ValueOf(&dest), DefaultLimits)
+		var m map[string]any
+		_ = node.Bind(reflect.ValueOf(&m), DefaultLimits)
+	})
+}
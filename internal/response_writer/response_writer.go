@@ -2,6 +2,7 @@ package response_writer
 
 import (
 	"bytes"
+	"maps"
 	"net/http"
 )
 
@@ -12,25 +13,34 @@ import (
 // out-of-band swaps and deferred rendering.
 type ResponseWriter struct {
 	http.ResponseWriter
-	body       *bytes.Buffer
-	statusCode int
+	header      http.Header
+	baseHeader  http.Header
+	body        *bytes.Buffer
+	statusCode  int
+	beforeFlush []func() error
+	flushed     bool
 }
 
 // NewResponseWriter creates a new ResponseWriter that wraps the provided
 // http.ResponseWriter with buffering capabilities.
 // The buffer is initially empty and the status code is set to 200 OK.
+// Headers already set on w are kept, while headers set through the
+// ResponseWriter are buffered like the body.
 func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
 	return &ResponseWriter{
 		ResponseWriter: w,
+		header:         w.Header().Clone(),
+		baseHeader:     w.Header().Clone(),
 		body:           new(bytes.Buffer),
 		statusCode:     http.StatusOK,
 	}
 }
 
 // Header returns the header map that will be sent to the client.
-// This is a passthrough to the underlying http.ResponseWriter's Header method.
+// Changes to it are not applied to the underlying http.ResponseWriter until
+// Flush is called, so they are discarded along with the body by Reset.
 func (rw *ResponseWriter) Header() http.Header {
-	return rw.ResponseWriter.Header()
+	return rw.header
 }
 
 // Write adds the provided bytes to the response buffer.
@@ -47,18 +57,53 @@ func (rw *ResponseWriter) WriteHeader(statusCode int) {
 	rw.statusCode = statusCode
 }
 
-// Reset clears the response buffer and resets the status code to 200 OK.
+// StatusCode returns the status code that will be sent when the response is flushed.
+func (rw *ResponseWriter) StatusCode() int {
+	return rw.statusCode
+}
+
+// Len returns the number of bytes currently buffered for the response body.
+func (rw *ResponseWriter) Len() int {
+	return rw.body.Len()
+}
+
+// Reset clears the response buffer, the headers set through the ResponseWriter
+// and resets the status code to 200 OK.
 // This is useful when an action needs to discard its current response and start over.
 func (rw *ResponseWriter) Reset() {
 	rw.body.Reset()
+	rw.header = rw.baseHeader.Clone()
 	rw.statusCode = http.StatusOK
 }
 
+// BeforeFlush registers a function that is called when Flush is called, before
+// anything is sent to the client, so it can still modify the headers.
+// Functions are called in the order they were registered.
+func (rw *ResponseWriter) BeforeFlush(fn func() error) {
+	rw.beforeFlush = append(rw.beforeFlush, fn)
+}
+
 // Flush writes the buffered response to the underlying ResponseWriter.
 // This sends the status code, headers, and body to the client.
 // It's called automatically at the end of request processing by the Gong server.
+// If a function registered with BeforeFlush fails, Flush returns its error
+// without sending anything.
 func (rw *ResponseWriter) Flush() error {
+	for _, fn := range rw.beforeFlush {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	rw.flushed = true
+	header := rw.ResponseWriter.Header()
+	clear(header)
+	maps.Copy(header, rw.header)
 	rw.ResponseWriter.WriteHeader(rw.statusCode)
 	_, err := rw.ResponseWriter.Write(rw.body.Bytes())
 	return err
 }
+
+// Flushed reports whether Flush has started sending the response to the client.
+func (rw *ResponseWriter) Flushed() bool {
+	return rw.flushed
+}
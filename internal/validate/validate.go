@@ -0,0 +1,257 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Func checks a field value against a rule. The param is the text after "="
+// in the rule, or empty if the rule has none. The returned error's message is
+// shown to the user, so it should complete a sentence about the field,
+// for example "must be a valid email address".
+type Func func(value any, param string) error
+
+var (
+	mu         sync.RWMutex
+	validators = map[string]Func{}
+)
+
+// Register adds a validator that can be used as a rule in validate tags.
+// Registering a name again replaces the previous validator, including the built-in ones.
+func Register(name string, fn Func) {
+	mu.Lock()
+	defer mu.Unlock()
+	validators[name] = fn
+}
+
+func lookup(name string) (Func, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	fn, ok := validators[name]
+	return fn, ok
+}
+
+// Errors maps the form keys of invalid fields to their error messages.
+// Keys use the bracket notation of the form field names, such as
+// "person[email]" or "people[0][email]".
+type Errors map[string]string
+
+func (errs Errors) Error() string {
+	keys := make([]string, 0, len(errs))
+	for key := range errs {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	var b strings.Builder
+	b.WriteString("validation failed:")
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s %s;", key, errs[key])
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+// Struct validates the fields of the struct that v points to against the
+// rules in their validate tags, such as `validate:"required,email,max=50"`.
+// Rules are separated by commas and run in order until one fails.
+// Fields are identified by their form tag, or by their name if they have none.
+// Nested structs, and slices and maps of structs, are validated recursively.
+// It returns nil if every field is valid.
+func Struct(v any) error {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Pointer || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	errs := Errors{}
+	if err := validateValue(val, "", errs); err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateValue(val reflect.Value, key string, errs Errors) error {
+	switch val.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+		return validateValue(val.Elem(), key, errs)
+	case reflect.Struct:
+		if val.Type().ConvertibleTo(timeType) {
+			return nil
+		}
+		t := val.Type()
+		for index := range val.NumField() {
+			field := t.Field(index)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Name
+			if formName, ok := field.Tag.Lookup("form"); ok {
+				name = formName
+			}
+			fieldKey := childKey(key, name)
+			if rules, ok := field.Tag.Lookup("validate"); ok {
+				if err := validateField(val.Field(index), rules, fieldKey, errs); err != nil {
+					return err
+				}
+			}
+			if err := validateValue(val.Field(index), fieldKey, errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range val.Len() {
+			if err := validateValue(val.Index(i), childKey(key, strconv.Itoa(i)), errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			if err := validateValue(iter.Value(), childKey(key, fmt.Sprint(iter.Key().Interface())), errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func childKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "[" + name + "]"
+}
+
+// validateField runs the rules against the field and records the first
+// failure in errs. Empty strings, slices, and maps and nil pointers are only
+// checked by the required rule, so optional fields may be left blank.
+func validateField(field reflect.Value, rules string, key string, errs Errors) error {
+	if _, ok := errs[key]; ok {
+		return nil
+	}
+	zero := field.IsZero()
+	for field.Kind() == reflect.Pointer && !field.IsNil() {
+		field = field.Elem()
+	}
+	var empty bool
+	switch field.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		empty = field.IsNil()
+	case reflect.String, reflect.Slice, reflect.Map:
+		empty = field.Len() == 0
+	}
+	for _, rule := range strings.Split(rules, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "required" {
+			if zero {
+				errs[key] = "is required"
+				return nil
+			}
+			continue
+		}
+		if empty {
+			return nil
+		}
+		fn, ok := lookup(name)
+		if !ok {
+			return fmt.Errorf("validate: unknown rule %q for field %q", name, key)
+		}
+		if err := fn(field.Interface(), param); err != nil {
+			errs[key] = err.Error()
+			return nil
+		}
+	}
+	return nil
+}
+
+func init() {
+	Register("email", validateEmail)
+	Register("min", validateMin)
+	Register("max", validateMax)
+	Register("len", validateLen)
+	Register("oneof", validateOneOf)
+}
+
+func validateEmail(value any, param string) error {
+	s, ok := value.(string)
+	if !ok {
+		return errors.New("must be a valid email address")
+	}
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return errors.New("must be a valid email address")
+	}
+	return nil
+}
+
+func validateMin(value any, param string) error {
+	return compare(value, param, func(n, limit float64) bool { return n >= limit }, "at least")
+}
+
+func validateMax(value any, param string) error {
+	return compare(value, param, func(n, limit float64) bool { return n <= limit }, "at most")
+}
+
+func validateLen(value any, param string) error {
+	return compare(value, param, func(n, limit float64) bool { return n == limit }, "exactly")
+}
+
+// compare checks the length of strings, slices, and maps, or the value of
+// numbers, against the limit in param.
+func compare(value any, param string, ok func(n, limit float64) bool, relation string) error {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return fmt.Errorf("has an invalid limit %q", param)
+	}
+	val := reflect.ValueOf(value)
+	var n float64
+	unit := ""
+	switch val.Kind() {
+	case reflect.String:
+		n = float64(len([]rune(val.String())))
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n = float64(val.Len())
+		unit = " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = val.Float()
+	default:
+		return fmt.Errorf("cannot be compared to %s", param)
+	}
+	if !ok(n, limit) {
+		return fmt.Errorf("must be %s %s%s", relation, param, unit)
+	}
+	return nil
+}
+
+func validateOneOf(value any, param string) error {
+	options := strings.Fields(param)
+	if slices.Contains(options, fmt.Sprint(value)) {
+		return nil
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(options, ", "))
+}
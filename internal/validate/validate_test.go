@@ -0,0 +1,136 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/troygilman/gong/internal/assert"
+)
+
+type Address struct {
+	City string `form:"city" validate:"required"`
+}
+
+type Signup struct {
+	Name      string    `form:"name" validate:"required,min=3,max=10"`
+	Email     string    `form:"email" validate:"required,email"`
+	Age       int       `form:"age" validate:"min=18"`
+	Nickname  string    `form:"nickname" validate:"min=3"`
+	Plan      string    `form:"plan" validate:"oneof=free pro"`
+	Tags      []string  `form:"tags" validate:"max=2"`
+	Address   Address   `form:"address"`
+	Addresses []Address `form:"addresses"`
+}
+
+func TestStruct(t *testing.T) {
+	valid := Signup{
+		Name:      "alice",
+		Email:     "alice@example.com",
+		Age:       30,
+		Plan:      "pro",
+		Tags:      []string{"a"},
+		Address:   Address{City: "Paris"},
+		Addresses: []Address{{City: "Rome"}},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Signup)
+		errs   Errors
+	}{
+		{
+			name:   "valid",
+			modify: func(s *Signup) {},
+			errs:   nil,
+		},
+		{
+			name:   "required",
+			modify: func(s *Signup) { s.Name = ""; s.Email = "" },
+			errs:   Errors{"name": "is required", "email": "is required"},
+		},
+		{
+			name:   "string length",
+			modify: func(s *Signup) { s.Name = "al"; s.Nickname = "abcdefghijk" },
+			errs:   Errors{"name": "must be at least 3 characters"},
+		},
+		{
+			name:   "too long",
+			modify: func(s *Signup) { s.Name = "abcdefghijk" },
+			errs:   Errors{"name": "must be at most 10 characters"},
+		},
+		{
+			name:   "email",
+			modify: func(s *Signup) { s.Email = "Alice <alice@example.com>" },
+			errs:   Errors{"email": "must be a valid email address"},
+		},
+		{
+			name:   "number",
+			modify: func(s *Signup) { s.Age = 0 },
+			errs:   Errors{"age": "must be at least 18"},
+		},
+		{
+			name:   "oneof",
+			modify: func(s *Signup) { s.Plan = "gold" },
+			errs:   Errors{"plan": "must be one of free, pro"},
+		},
+		{
+			name:   "optional empty",
+			modify: func(s *Signup) { s.Plan = ""; s.Nickname = "" },
+			errs:   nil,
+		},
+		{
+			name:   "slice length",
+			modify: func(s *Signup) { s.Tags = []string{"a", "b", "c"} },
+			errs:   Errors{"tags": "must be at most 2 items"},
+		},
+		{
+			name:   "nested",
+			modify: func(s *Signup) { s.Address.City = ""; s.Addresses = append(s.Addresses, Address{}) },
+			errs:   Errors{"address[city]": "is required", "addresses[1][city]": "is required"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signup := valid
+			signup.Addresses = append([]Address(nil), valid.Addresses...)
+			test.modify(&signup)
+
+			err := Struct(&signup)
+			if test.errs == nil {
+				assert.NoErr(t, err)
+				return
+			}
+			var errs Errors
+			assert.Equals(t, true, errors.As(err, &errs))
+			assert.Equals(t, test.errs, errs)
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("even", func(value any, param string) error {
+		if value.(int)%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+
+	type form struct {
+		Count int `form:"count" validate:"even"`
+	}
+
+	assert.NoErr(t, Struct(&form{Count: 2}))
+	assert.Equals(t, Errors{"count": "must be even"}, Struct(&form{Count: 3}))
+}
+
+func TestStruct_unknownRule(t *testing.T) {
+	type form struct {
+		Name string `validate:"unknown"`
+	}
+
+	err := Struct(&form{Name: "x"})
+	assert.Err(t, err)
+	var errs Errors
+	assert.Equals(t, false, errors.As(err, &errs))
+}
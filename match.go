@@ -0,0 +1,73 @@
+package gong
+
+import (
+	"net/url"
+	"strings"
+)
+
+// matchPattern matches a request path against a ServeMux-style route pattern,
+// one segment at a time. As with ServeMux, a pattern ending in "/" also matches
+// every path below it, while other patterns only match paths with the same
+// segments. A "{name}" segment matches any single segment, a "{name...}"
+// segment matches the remainder of the path, and "{$}" only matches the end of
+// the path. The values of the wildcards are returned keyed by name.
+func matchPattern(pattern string, path string) (map[string]string, bool) {
+	params := make(map[string]string)
+	patternSegments := splitPath(pattern)
+	pathSegments := splitPath(path)
+	for i, segment := range patternSegments {
+		if segment == "{$}" {
+			return params, i == len(pathSegments)
+		}
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			name = strings.TrimSuffix(name, "}")
+			if name, ok := strings.CutSuffix(name, "..."); ok {
+				params[name] = unescapePath(strings.Join(pathSegments[min(i, len(pathSegments)):], "/"))
+				return params, true
+			}
+			if i >= len(pathSegments) {
+				return nil, false
+			}
+			params[name] = unescapePath(pathSegments[i])
+			continue
+		}
+		if i >= len(pathSegments) || segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	// The empty pattern of the root of the route tree matches every path.
+	if pattern != "" && !strings.HasSuffix(pattern, "/") {
+		if len(pathSegments) != len(patternSegments) || (strings.HasSuffix(path, "/") && path != "/") {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// matchPatternExact reports whether the pattern matches the whole path,
+// rather than only a path below it.
+func matchPatternExact(pattern string, path string) bool {
+	if _, ok := matchPattern(pattern, path); !ok {
+		return false
+	}
+	patternSegments := splitPath(pattern)
+	if n := len(patternSegments); n > 0 && strings.HasSuffix(patternSegments[n-1], "...}") {
+		return true
+	}
+	return len(splitPath(path)) <= len(patternSegments)
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func unescapePath(path string) string {
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		return unescaped
+	}
+	return path
+}
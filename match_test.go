@@ -0,0 +1,108 @@
+package gong
+
+import (
+	"testing"
+
+	"github.com/troygilman/gong/internal/assert"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		path    string
+		params  map[string]string
+		ok      bool
+	}{
+		{
+			name:    "root matches everything",
+			pattern: "/",
+			path:    "/users",
+			params:  map[string]string{},
+			ok:      true,
+		},
+		{
+			name:    "exact",
+			pattern: "/users",
+			path:    "/users",
+			params:  map[string]string{},
+			ok:      true,
+		},
+		{
+			name:    "prefix of segment does not match",
+			pattern: "/user",
+			path:    "/users",
+			ok:      false,
+		},
+		{
+			name:    "pattern ending in slash matches paths below it",
+			pattern: "/users/",
+			path:    "/users/1",
+			params:  map[string]string{},
+			ok:      true,
+		},
+		{
+			name:    "pattern without trailing slash does not match paths below it",
+			pattern: "/users",
+			path:    "/users/1",
+			ok:      false,
+		},
+		{
+			name:    "pattern without trailing slash does not match trailing slash",
+			pattern: "/users",
+			path:    "/users/",
+			ok:      false,
+		},
+		{
+			name:    "wildcard",
+			pattern: "/user/{name}/",
+			path:    "/user/bob/",
+			params:  map[string]string{"name": "bob"},
+			ok:      true,
+		},
+		{
+			name:    "escaped wildcard",
+			pattern: "/user/{name}",
+			path:    "/user/bob%20smith",
+			params:  map[string]string{"name": "bob smith"},
+			ok:      true,
+		},
+		{
+			name:    "missing wildcard segment",
+			pattern: "/user/{name}/",
+			path:    "/user/",
+			ok:      false,
+		},
+		{
+			name:    "rest wildcard",
+			pattern: "/files/{path...}",
+			path:    "/files/a/b/c",
+			params:  map[string]string{"path": "a/b/c"},
+			ok:      true,
+		},
+		{
+			name:    "empty rest wildcard",
+			pattern: "/files/{path...}",
+			path:    "/files/",
+			params:  map[string]string{"path": ""},
+			ok:      true,
+		},
+		{
+			name:    "end anchor",
+			pattern: "/{$}",
+			path:    "/users",
+			params:  nil,
+			ok:      false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params, ok := matchPattern(test.pattern, test.path)
+			assert.Equals(t, test.ok, ok)
+			if test.ok {
+				assert.Equals(t, test.params, params)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package gong
+
+import "net/http"
+
+// Middleware wraps an http.Handler with additional behavior such as logging,
+// authentication, or panic recovery. Middleware is applied in the order it is
+// registered, so the first middleware is the outermost.
+type Middleware func(http.Handler) http.Handler
+
+// chainMiddleware wraps the handler with the given middleware so that the
+// first middleware in the slice is the first to receive the request.
+func chainMiddleware(handler http.Handler, middleware []Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
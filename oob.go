@@ -0,0 +1,84 @@
+package gong
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+// OOB renders another component into the current response as an out of band
+// swap, so an action can update parts of the page outside its own target.
+//
+// If component is a Component in the current route or one of the layouts
+// around it, its View is rendered in the context of the route it belongs to
+// and the Target in that View is marked with hx-swap-oob, so it replaces the
+// Target already on the page. The component is looked up by identity, from the
+// innermost route outwards, and must occur only once in the route it is found in.
+// Any other templ.Component is wrapped in an element with the ID given by
+// WithID, which replaces the element with that ID.
+// The swap strategy defaults to outerHTML and can be changed with WithSwap.
+func OOB(ctx context.Context, component templ.Component, opts ...ElementOption) error {
+	gCtx := getContext(ctx)
+	c := elementConfig{
+		swap: SwapOuterHTML,
+	}
+	for _, opt := range opts {
+		c = opt(c)
+	}
+
+	if component, ok := component.(Component); ok && c.id == "" {
+		node, match, err := findOOBComponent(gCtx.Node, component)
+		if err != nil {
+			return err
+		}
+		gCtx.Node = node
+		gCtx.ChildRouteIndex = 0
+		gCtx.ComponentID = ""
+		if i := strings.LastIndex(match.path, idDelimeter); i >= 0 {
+			gCtx.ComponentID = match.path[:i]
+		}
+		gCtx.OOB = c.swap
+		gCtx.OOBComponentID = match.path
+		return render(ctx, gCtx, gCtx.Writer, match.component.View())
+	}
+
+	if c.id == "" {
+		return errors.New("gong: OOB requires WithID for components that are not part of the route")
+	}
+	return render(templ.WithChildren(ctx, component), gCtx, gCtx.Writer, oobWrapper(c.id, c.swap))
+}
+
+// findOOBComponent finds component in the route of node or, failing that, in
+// the nearest of its ancestors that contains it. It returns an error if the
+// component is not found or occurs more than once in that route.
+func findOOBComponent(node *routeNode, component Component) (*routeNode, componentMatch, error) {
+	for n := node; n != nil; n = n.parent {
+		matches := findComponent(n.route.component, component)
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return n, matches[0], nil
+		default:
+			paths := make([]string, len(matches))
+			for i, match := range matches {
+				paths[i] = match.path
+			}
+			return nil, componentMatch{}, fmt.Errorf("gong: component %q occurs more than once in route %q: %s", component.ID(), n.path, strings.Join(paths, ", "))
+		}
+	}
+	return nil, componentMatch{}, fmt.Errorf("%w: no component with id %q in route %q or its layouts", ErrComponentNotFound, component.ID(), node.path)
+}
+
+// oobSwap returns the out of band swap strategy for a Target rendered by the
+// component currently being swapped with OOB, or an empty string otherwise.
+func oobSwap(ctx context.Context) string {
+	gCtx := getContext(ctx)
+	if gCtx.OOB != "" && gCtx.ComponentID == gCtx.OOBComponentID {
+		return gCtx.OOB
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package gong
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/troygilman/gong/internal/assert"
+)
+
+type testOOBComponent struct {
+	Counter Component
+}
+
+func (c testOOBComponent) View() templ.Component {
+	return c.Counter
+}
+
+func (c testOOBComponent) Action() templ.Component {
+	return RenderFunc(func(ctx context.Context, w io.Writer) error {
+		if _, err := io.WriteString(w, "result"); err != nil {
+			return err
+		}
+		if err := OOB(ctx, c.Counter); err != nil {
+			return err
+		}
+		return OOB(ctx, testTemplComponent{text: "sidebar"}, WithID("sidebar"), WithSwap(SwapInnerHTML))
+	})
+}
+
+func TestOOB(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testOOBComponent{
+		Counter: NewComponent(testComponent{view: Target()}),
+	})))
+
+	r := newTestActionRequest(routeSegment("/"), "root")
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	body := rec.Body.String()
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, true, strings.HasPrefix(body, "result"))
+	assert.Equals(t, true, strings.Contains(body, `<div id="gong_`+routeSegment("/")+`_root_Counter"`))
+	assert.Equals(t, true, strings.Contains(body, `hx-swap-oob="outerHTML"`))
+	assert.Equals(t, true, strings.Contains(body, `<div id="sidebar" hx-swap-oob="innerHTML">sidebar</div>`))
+}
+
+type testSidebarLayout struct {
+	Counter Component
+}
+
+func (c testSidebarLayout) View() templ.Component {
+	return RenderFunc(func(ctx context.Context, w io.Writer) error {
+		if err := c.Counter.Render(ctx, w); err != nil {
+			return err
+		}
+		return Outlet().Render(ctx, w)
+	})
+}
+
+func TestOOB_layoutComponent(t *testing.T) {
+	counter := NewComponent(testComponent{view: Target()})
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testSidebarLayout{Counter: counter}), WithChildren(
+		NewRoute("items", NewComponent(testComponent{
+			action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+				return OOB(ctx, counter)
+			}),
+		})),
+	)))
+
+	r := newTestActionRequest(joinRouteID(routeSegment("/"), routeSegment("items")), "root")
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	body := rec.Body.String()
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, true, strings.Contains(body, `<div id="gong_`+routeSegment("/")+`_root_Counter"`))
+	assert.Equals(t, true, strings.Contains(body, `&#34;Gong-Route-ID&#34;: &#34;`+routeSegment("/")+`&#34;`))
+	assert.Equals(t, true, strings.Contains(body, `hx-swap-oob="outerHTML"`))
+}
+
+type testSharedComponent struct {
+	First  Component
+	Second Component
+}
+
+func (c testSharedComponent) View() templ.Component {
+	return nil
+}
+
+func (c testSharedComponent) Action() templ.Component {
+	return RenderFunc(func(ctx context.Context, w io.Writer) error {
+		return OOB(ctx, c.First)
+	})
+}
+
+func TestOOB_ambiguous(t *testing.T) {
+	var handled error
+	shared := NewComponent(testComponent{view: Target()})
+	svr := NewServer(WithErrorHandler(func(ctx context.Context, err error) {
+		handled = err
+	}))
+	svr.Route(NewRoute("/", NewComponent(testSharedComponent{First: shared, Second: shared})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestActionRequest(routeSegment("/"), "root"))
+
+	assert.Equals(t, http.StatusInternalServerError, rec.Code)
+	assert.Equals(t, true, strings.Contains(handled.Error(), "more than once"))
+}
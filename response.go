@@ -0,0 +1,70 @@
+package gong
+
+import (
+	"context"
+	"net/http"
+)
+
+// htmx request and response headers.
+const (
+	HeaderHXRequest    = "HX-Request"
+	HeaderHXCurrentURL = "HX-Current-URL"
+	HeaderHXRedirect   = "HX-Redirect"
+	HeaderHXLocation   = "HX-Location"
+	HeaderHXPushURL    = "HX-Push-Url"
+	HeaderHXReplaceURL = "HX-Replace-Url"
+	HeaderHXRetarget   = "HX-Retarget"
+	HeaderHXReswap     = "HX-Reswap"
+	HeaderHXTrigger    = "HX-Trigger"
+)
+
+// Redirect sends the client to the specified path.
+// For htmx requests it sets the HX-Redirect header so the browser performs a
+// full navigation instead of swapping the redirected page into the target.
+// Other requests receive a 303 (See Other) redirect.
+// Any response written so far is discarded.
+func Redirect(ctx context.Context, path string) error {
+	gCtx := getContext(ctx)
+	gCtx.Writer.Reset()
+	if isHTMXRequest(gCtx.Request) {
+		gCtx.Writer.Header().Set(HeaderHXRedirect, path)
+		return nil
+	}
+	http.Redirect(gCtx.Writer, gCtx.Request, path, http.StatusSeeOther)
+	return nil
+}
+
+// Location makes htmx navigate to the path without a full page reload,
+// as if a boosted link to it had been followed.
+func Location(ctx context.Context, path string) {
+	Header(ctx).Set(HeaderHXLocation, path)
+}
+
+// PushURL pushes the URL into the browser's history stack.
+func PushURL(ctx context.Context, url string) {
+	Header(ctx).Set(HeaderHXPushURL, url)
+}
+
+// ReplaceURL replaces the current URL in the browser's location bar.
+func ReplaceURL(ctx context.Context, url string) {
+	Header(ctx).Set(HeaderHXReplaceURL, url)
+}
+
+// Retarget swaps the response into the element matching the CSS selector
+// instead of the target of the element that sent the request.
+func Retarget(ctx context.Context, selector string) {
+	Header(ctx).Set(HeaderHXRetarget, selector)
+}
+
+// Reswap overrides how the response is swapped, using one of the Swap constants
+// optionally followed by htmx swap modifiers.
+func Reswap(ctx context.Context, swap string) {
+	Header(ctx).Set(HeaderHXReswap, swap)
+}
+
+// Trigger makes htmx dispatch the event on the client once the response is received.
+// The payload is encoded as JSON and available to client code as the event detail.
+// Triggering the same event twice in a response keeps the last payload.
+func Trigger(ctx context.Context, event string, payload any) error {
+	return addTrigger(Header(ctx), HeaderHXTrigger, event, payload)
+}
@@ -0,0 +1,98 @@
+package gong
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/troygilman/gong/internal/assert"
+	"github.com/troygilman/gong/internal/response_writer"
+)
+
+func newTestResponseContext(r *http.Request) (context.Context, *httptest.ResponseRecorder, *response_writer.ResponseWriter) {
+	rec := httptest.NewRecorder()
+	writer := response_writer.NewResponseWriter(rec)
+	return setContext(context.Background(), gongContext{Request: r, Writer: writer}), rec, writer
+}
+
+func TestRedirect(t *testing.T) {
+	ctx, rec, writer := newTestResponseContext(newTestRequest(http.MethodPost, "/"))
+
+	assert.NoErr(t, Redirect(ctx, "/login"))
+	assert.NoErr(t, writer.Flush())
+
+	assert.Equals(t, http.StatusSeeOther, rec.Code)
+	assert.Equals(t, "/login", rec.Header().Get("Location"))
+}
+
+func TestRedirect_htmx(t *testing.T) {
+	r := newTestRequest(http.MethodPost, "/")
+	r.Header.Set(HeaderHXRequest, "true")
+	ctx, rec, writer := newTestResponseContext(r)
+
+	_, err := writer.Write([]byte("discarded"))
+	assert.NoErr(t, err)
+	assert.NoErr(t, Redirect(ctx, "/login"))
+	assert.NoErr(t, writer.Flush())
+
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, "/login", rec.Header().Get(HeaderHXRedirect))
+	assert.Equals(t, "", rec.Body.String())
+}
+
+func TestRedirect_discardsHeaders(t *testing.T) {
+	r := newTestRequest(http.MethodPost, "/")
+	r.Header.Set(HeaderHXRequest, "true")
+	ctx, rec, writer := newTestResponseContext(r)
+
+	Retarget(ctx, "#x")
+	assert.NoErr(t, Trigger(ctx, "user.created", 1))
+	assert.NoErr(t, Redirect(ctx, "/login"))
+	assert.NoErr(t, writer.Flush())
+
+	assert.Equals(t, "/login", rec.Header().Get(HeaderHXRedirect))
+	assert.Equals(t, "", rec.Header().Get(HeaderHXRetarget))
+	assert.Equals(t, "", rec.Header().Get(HeaderHXTrigger))
+}
+
+func TestResponseHeaders(t *testing.T) {
+	ctx, _, writer := newTestResponseContext(newTestRequest(http.MethodPost, "/"))
+
+	Location(ctx, "/users")
+	PushURL(ctx, "/users/1")
+	ReplaceURL(ctx, "/users/2")
+	Retarget(ctx, "#errors")
+	Reswap(ctx, SwapBeforeEnd)
+	assert.NoErr(t, Trigger(ctx, "saved", map[string]int{"id": 1}))
+
+	header := writer.Header()
+	assert.Equals(t, "/users", header.Get(HeaderHXLocation))
+	assert.Equals(t, "/users/1", header.Get(HeaderHXPushURL))
+	assert.Equals(t, "/users/2", header.Get(HeaderHXReplaceURL))
+	assert.Equals(t, "#errors", header.Get(HeaderHXRetarget))
+	assert.Equals(t, SwapBeforeEnd, header.Get(HeaderHXReswap))
+	assert.Equals(t, `{"saved":{"id":1}}`, header.Get(HeaderHXTrigger))
+}
+
+func TestResponseHeaders_discardedOnError(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			Retarget(ctx, "#x")
+			if err := Trigger(ctx, "user.created", 1); err != nil {
+				return err
+			}
+			return errors.New("action failed")
+		}),
+	}, WithComponentID("mock"))))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestActionRequest(routeSegment("/"), "mock"))
+
+	assert.Equals(t, http.StatusInternalServerError, rec.Code)
+	assert.Equals(t, "", rec.Header().Get(HeaderHXRetarget))
+	assert.Equals(t, "", rec.Header().Get(HeaderHXTrigger))
+}
@@ -5,8 +5,12 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"strconv"
+	"maps"
+	"net/http"
+	"net/url"
 	"strings"
+
+	"github.com/a-h/templ"
 )
 
 type RouteOption func(Route) Route
@@ -18,16 +22,76 @@ func WithChildren(children ...Route) RouteOption {
 	}
 }
 
+// WithRouteMiddleware adds middleware that wraps requests handled by the route
+// and all of its children, including action requests targeting them.
+func WithRouteMiddleware(middleware ...Middleware) RouteOption {
+	return func(r Route) Route {
+		r.middleware = append(r.middleware, middleware...)
+		return r
+	}
+}
+
+// WithGuard adds guards that run before any View or Action of the route and
+// all of its children, after the route's middleware. Guards of outer routes
+// run before guards of inner routes, and the first guard that returns an
+// error stops the request.
+func WithGuard(guards ...Guard) RouteOption {
+	return func(r Route) Route {
+		r.guards = append(r.guards, guards...)
+		return r
+	}
+}
+
+// WithName gives the route a name that can be used to build its URL with URL
+// or to link to it with Link. Names must be unique across the server.
+func WithName(name string) RouteOption {
+	return func(r Route) Route {
+		r.name = name
+		r.named = true
+		return r
+	}
+}
+
+// WithRouteNotFound sets the component rendered in the nearest outlet when a
+// request path falls under this route, or one of its children, but matches no route.
+func WithRouteNotFound(component Component) RouteOption {
+	return func(r Route) Route {
+		r.notFound = component
+		return r
+	}
+}
+
+// WithRouteErrorComponent sets the component rendered in place of a View or
+// Action of this route, or one of its children, that returns an error.
+// The error is available to the component through RenderError.
+func WithRouteErrorComponent(component Component) RouteOption {
+	return func(r Route) Route {
+		r.errorComponent = component
+		return r
+	}
+}
+
 type Route struct {
-	path      string
-	component Component
-	children  []Route
+	path           string
+	name           string
+	named          bool
+	component      Component
+	children       []Route
+	middleware     []Middleware
+	guards         []Guard
+	notFound       Component
+	errorComponent Component
 }
 
 func (r Route) Path() string {
 	return r.path
 }
 
+// Name returns the name given to the route with WithName.
+func (r Route) Name() string {
+	return r.name
+}
+
 func NewRoute(path string, component Component, opts ...RouteOption) Route {
 	route := Route{
 		path:      path,
@@ -39,29 +103,59 @@ func NewRoute(path string, component Component, opts ...RouteOption) Route {
 	return route
 }
 
-func (route Route) newNode(parent *routeNode, id string) *routeNode {
+// newNode builds the routeNode tree for the route and its children.
+// Each node's ID is its parent's ID followed by a segment derived from the
+// route's own path, so IDs stay stable when sibling routes are added or reordered.
+func (route Route) newNode(parent *routeNode) *routeNode {
+	route.component = withDefaultID(route.component, rootComponentID)
+	if route.notFound != nil {
+		route.notFound = withDefaultID(route.notFound, notFoundComponentID)
+	}
+	if route.errorComponent != nil {
+		route.errorComponent = withDefaultID(route.errorComponent, errorComponentID)
+	}
 	node := &routeNode{
-		route:  route,
-		parent: parent,
-		id:     id,
-		depth:  len(id),
+		route:          route,
+		parent:         parent,
+		notFound:       route.notFound,
+		errorComponent: route.errorComponent,
 	}
 	if parent != nil {
 		node.path = parent.path + route.path
+		node.segment = routeSegment(route.path)
+		node.id = joinRouteID(parent.id, node.segment)
+		node.depth = parent.depth + 1
+		node.middleware = append(node.middleware, parent.middleware...)
+		node.guards = append(node.guards, parent.guards...)
+		if node.notFound == nil {
+			node.notFound = parent.notFound
+		}
+		if node.errorComponent == nil {
+			node.errorComponent = parent.errorComponent
+		}
 	}
+	node.middleware = append(node.middleware, route.middleware...)
+	node.guards = append(node.guards, route.guards...)
 	for _, child := range route.children {
-		node.children = append(node.children, child.newNode(node, id+strconv.Itoa(len(node.children))))
+		node.children = append(node.children, child.newNode(node))
 	}
 	return node
 }
 
 type routeNode struct {
-	route    Route
-	path     string
-	id       string
-	depth    int
-	parent   *routeNode
-	children []*routeNode
+	route      Route
+	path       string
+	segment    string
+	id         string
+	depth      int
+	parent     *routeNode
+	children   []*routeNode
+	middleware []Middleware
+	guards     []Guard
+	names      map[string]*routeNode
+	// notFound and errorComponent are the nearest fallbacks set on this route or its ancestors.
+	notFound       Component
+	errorComponent Component
 }
 
 func (node *routeNode) Render(ctx context.Context, w io.Writer) error {
@@ -71,21 +165,26 @@ func (node *routeNode) Render(ctx context.Context, w io.Writer) error {
 
 	// log.Printf("Rendering Route: %+v\n", gCtx)
 	if len(node.children) > 0 {
-		if len(gCtx.RouteID) > node.depth {
-			gCtx.ChildRouteIndex = int(gCtx.RouteID[node.depth] - '0')
-		}
+		gCtx.ChildRouteIndex = node.childIndex(gCtx.RouteID)
 	}
 
 	if gCtx.Action {
 		component, ok := node.route.component.Find(gCtx.ComponentID)
 		if !ok {
-			panic(fmt.Sprintf("could not find component with id %s in route %s", gCtx.ComponentID, node.route.path))
+			return fmt.Errorf("%w: no component with id %q in route %q", ErrComponentNotFound, gCtx.ComponentID, node.path)
 		}
-		return render(ctx, gCtx, w, component.Action())
+		return renderWithFallback(ctx, gCtx, w, component.Action(), node.errorFallback())
 	}
 
-	if node.matchPath(gCtx.RenderedPath) {
+	if node.matchPath(gCtx.RenderedPath, requestPath(gCtx)) {
 		log.Println("should not render - node", node, ", children", node.children, ", rendered", gCtx.RenderedPath)
+		if node == gCtx.NotFound && gCtx.Link {
+			gCtx.Link = false
+			if err := renderHeadOOB(ctx, gCtx, w); err != nil {
+				return err
+			}
+			return render(ctx, gCtx, w, Outlet(withOOB(SwapInnerHTML)))
+		}
 		if len(node.children) == 0 {
 			return nil
 		}
@@ -95,28 +194,245 @@ func (node *routeNode) Render(ctx context.Context, w io.Writer) error {
 
 	if gCtx.Link {
 		gCtx.Link = false
+		if err := renderHeadOOB(ctx, gCtx, w); err != nil {
+			return err
+		}
 		gCtx.Node = node.parent
-		return render(ctx, gCtx, w, Outlet(withOOB(true), withNode(node)))
+		return render(ctx, gCtx, w, Outlet(withOOB(SwapInnerHTML), withNode(node)))
 	}
 
 	gCtx.ComponentID = ""
-	return render(ctx, gCtx, w, node.route.component.View())
+	return renderWithFallback(ctx, gCtx, w, node.route.component.View(), node.errorFallback())
+}
+
+// renderHeadOOB renders the head elements of the active route chain as out of
+// band swaps for a link request.
+func renderHeadOOB(ctx context.Context, gCtx gongContext, w io.Writer) error {
+	gCtx.Node = gCtx.Node.root()
+	return render(ctx, gCtx, w, headOOB())
+}
+
+// outlet returns the content of the node's outlet: the not found component if
+// the request matched no route below this node, or else the active child route.
+func (node *routeNode) outlet(gCtx gongContext) templ.Component {
+	if node == gCtx.NotFound {
+		return RenderFunc(func(ctx context.Context, w io.Writer) error {
+			gCtx := getContext(ctx)
+			gCtx.ComponentID = ""
+			return render(ctx, gCtx, w, node.notFound.View())
+		})
+	}
+	if gCtx.ChildRouteIndex < len(node.children) {
+		return node.children[gCtx.ChildRouteIndex]
+	}
+	return nil
 }
 
+// notFoundHost returns the node whose outlet shows the not found component
+// for a request that was routed to this node but does not match it exactly.
+func (node *routeNode) notFoundHost() *routeNode {
+	if len(node.children) == 0 && node.parent != nil {
+		return node.parent
+	}
+	return node
+}
+
+// errorFallback returns the component rendered when the node's View or Action fails,
+// or nil if neither the route nor its ancestors set an error component.
+// Full page requests are answered with status 500, while htmx requests keep
+// their status so the fallback is swapped into the target.
+func (node *routeNode) errorFallback() templ.Component {
+	if node.errorComponent == nil {
+		return nil
+	}
+	return RenderFunc(func(ctx context.Context, w io.Writer) error {
+		gCtx := getContext(ctx)
+		if gCtx.Writer != nil && !isHTMXRequest(gCtx.Request) {
+			gCtx.Writer.WriteHeader(http.StatusInternalServerError)
+		}
+		gCtx.ComponentID = ""
+		return render(ctx, gCtx, w, node.errorComponent.View())
+	})
+}
+
+// find returns the descendant node with the given route ID,
+// or nil if no such node exists.
 func (node *routeNode) find(id string) *routeNode {
-	var n *routeNode = node
-	for _, index := range id {
-		n = n.children[int(index-'0')]
+	n := node
+	for _, segment := range splitRouteID(id) {
+		i := n.childSegmentIndex(segment)
+		if i < 0 {
+			return nil
+		}
+		n = n.children[i]
 	}
 	return n
 }
 
-func (node *routeNode) matchPath(path string) bool {
-	if path == "" {
+// childIndex returns the index of the child on the path to the route with the
+// given ID, defaulting to the first child when the ID does not descend below this node.
+func (node *routeNode) childIndex(routeID string) int {
+	segments := splitRouteID(routeID)
+	if len(segments) > node.depth {
+		if i := node.childSegmentIndex(segments[node.depth]); i >= 0 {
+			return i
+		}
+	}
+	return 0
+}
+
+// chain returns the nodes below this node on the path to the route with the
+// given ID, ordered from the outermost to the innermost route.
+func (node *routeNode) chain(routeID string) []*routeNode {
+	var nodes []*routeNode
+	for n := node; len(n.children) > 0; {
+		n = n.children[n.childIndex(routeID)]
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+func (node *routeNode) childSegmentIndex(segment string) int {
+	for i, child := range node.children {
+		if child.segment == segment {
+			return i
+		}
+	}
+	return -1
+}
+
+// validate checks that no two sibling routes share a route ID segment
+// and that every route's component tree has valid, unique IDs.
+func (node *routeNode) validate() error {
+	for _, component := range []Component{node.route.component, node.route.notFound, node.route.errorComponent} {
+		if component == nil {
+			continue
+		}
+		if err := validateComponent(component); err != nil {
+			return fmt.Errorf("%w in route %q", err, node.path)
+		}
+	}
+	segments := make(map[string]*routeNode, len(node.children))
+	for _, child := range node.children {
+		if other, ok := segments[child.segment]; ok {
+			return fmt.Errorf("gong: routes %q and %q have the same route id %q", other.path, child.path, child.id)
+		}
+		segments[child.segment] = child
+		if err := child.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchPath reports whether the node is already rendered in the client.
+// This is the case when both the rendered path and the requested path match
+// the node's pattern and bind the same values to its wildcards, so navigating
+// between them does not change the node's content.
+func (node *routeNode) matchPath(renderedPath string, requestedPath string) bool {
+	if renderedPath == "" {
+		return false
+	}
+	renderedParams, ok := matchPattern(node.path, renderedPath)
+	if !ok {
 		return false
 	}
-	if node.path == "" && path != "" {
-		return true
+	requestedParams, ok := matchPattern(node.path, requestedPath)
+	if !ok {
+		return false
+	}
+	return maps.Equal(renderedParams, requestedParams)
+}
+
+func requestPath(gCtx gongContext) string {
+	if gCtx.Request == nil {
+		return ""
+	}
+	return gCtx.Request.URL.EscapedPath()
+}
+
+// indexNames records every named route below the node in the node's names map.
+// It returns an error if a name is empty or used by more than one route.
+func (node *routeNode) indexNames() error {
+	node.names = make(map[string]*routeNode)
+	return node.walk(func(n *routeNode) error {
+		if !n.route.named {
+			return nil
+		}
+		if n.route.name == "" {
+			return fmt.Errorf("gong: route %q has an empty name", n.path)
+		}
+		if other, ok := node.names[n.route.name]; ok {
+			return fmt.Errorf("gong: routes %q and %q are both named %q", other.path, n.path, n.route.name)
+		}
+		node.names[n.route.name] = n
+		return nil
+	})
+}
+
+// walk calls fn for the node and each of its descendants in depth-first order.
+func (node *routeNode) walk(fn func(*routeNode) error) error {
+	if err := fn(node); err != nil {
+		return err
+	}
+	for _, child := range node.children {
+		if err := child.walk(fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// root returns the root of the tree the node belongs to.
+func (node *routeNode) root() *routeNode {
+	n := node
+	for n.parent != nil {
+		n = n.parent
+	}
+	return n
+}
+
+// buildURL substitutes params, given as alternating names and values, into
+// the wildcards of the node's path. Values are path-escaped, except that the
+// slashes in a "{name...}" value are kept as path separators.
+func (node *routeNode) buildURL(params ...string) (string, error) {
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("gong: route %q: params must be name and value pairs", node.route.name)
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	segments := strings.Split(node.path, "/")
+	for i, segment := range segments {
+		if segment == "{$}" {
+			segments[i] = ""
+			continue
+		}
+		name, ok := strings.CutPrefix(segment, "{")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSuffix(name, "}")
+		name, rest := strings.CutSuffix(name, "...")
+		value, ok := values[name]
+		if !ok {
+			return "", fmt.Errorf("gong: route %q: missing param %q", node.route.name, name)
+		}
+		delete(values, name)
+		if rest {
+			parts := strings.Split(value, "/")
+			for j, part := range parts {
+				parts[j] = url.PathEscape(part)
+			}
+			segments[i] = strings.Join(parts, "/")
+		} else {
+			segments[i] = url.PathEscape(value)
+		}
+	}
+	for name := range values {
+		return "", fmt.Errorf("gong: route %q: unknown param %q", node.route.name, name)
 	}
-	return strings.HasPrefix(path, node.path)
+	return strings.Join(segments, "/"), nil
 }
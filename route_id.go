@@ -0,0 +1,54 @@
+package gong
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// Route IDs identify a route node in the Gong-Route-ID header and in element IDs.
+// An ID is a sequence of segments joined by routeIDDelimiter, one segment per
+// level below the root, where each segment is a hash of that route's own path.
+const (
+	routeIDDelimiter     = "-"
+	routeIDSegmentLength = 8
+)
+
+// routeSegment returns the route ID segment for a route path.
+func routeSegment(path string) string {
+	h := fnv.New32a()
+	h.Write([]byte(path))
+	return fmt.Sprintf("%0*x", routeIDSegmentLength, h.Sum32())
+}
+
+func joinRouteID(parentID string, segment string) string {
+	if parentID == "" {
+		return segment
+	}
+	return parentID + routeIDDelimiter + segment
+}
+
+func splitRouteID(id string) []string {
+	if id == "" {
+		return nil
+	}
+	return strings.Split(id, routeIDDelimiter)
+}
+
+// validRouteID reports whether id is a well-formed, non-root route ID.
+func validRouteID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, segment := range splitRouteID(id) {
+		if len(segment) != routeIDSegmentLength {
+			return false
+		}
+		for _, c := range segment {
+			if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -2,6 +2,7 @@ package gong
 
 import (
 	"net/http"
+	"strconv"
 	"testing"
 
 	"github.com/troygilman/gong/internal/assert"
@@ -12,7 +13,7 @@ func TestRouteBasic(t *testing.T) {
 		view: testTemplComponent{text: "view"},
 	}
 
-	node := NewRoute("/", NewComponent(comp, withID("mock"))).newNode(nil, "")
+	node := NewRoute("/", NewComponent(comp, WithComponentID("mock"))).newNode(nil)
 
 	assert.Equals(t, "/", node.route.Path())
 
@@ -28,7 +29,7 @@ func TestRouteRenderAction(t *testing.T) {
 		action: testTemplComponent{text: "action"},
 	}
 
-	node := NewRoute("/", NewComponent(comp, withID("mock"))).newNode(nil, "")
+	node := NewRoute("/", NewComponent(comp, WithComponentID("mock"))).newNode(nil)
 
 	ctx := gongContext{
 		Action:      true,
@@ -45,7 +46,7 @@ func TestRouteRenderAction_withLoader(t *testing.T) {
 		loaderData: "action",
 	}
 
-	node := NewRoute("/", NewComponent(comp, withID("mock"))).newNode(nil, "")
+	node := NewRoute("/", NewComponent(comp, WithComponentID("mock"))).newNode(nil)
 
 	ctx := gongContext{
 		Action:      true,
@@ -55,3 +56,82 @@ func TestRouteRenderAction_withLoader(t *testing.T) {
 
 	testRender(t, node, ctx, "action")
 }
+
+func TestRouteFind_manyChildren(t *testing.T) {
+	var children []Route
+	for i := range 12 {
+		children = append(children, NewRoute(strconv.Itoa(i), NewComponent(testComponent{})))
+	}
+
+	root := NewRoute("/", NewComponent(testComponent{}), WithChildren(children...)).newNode(nil)
+	assert.NoErr(t, root.validate())
+
+	for i, child := range root.children {
+		assert.Equals(t, child, root.find(child.id))
+		assert.Equals(t, i, root.childIndex(child.id))
+	}
+	assert.Equals(t, true, root.find("ffffffff") == nil)
+}
+
+func TestRouteID_stable(t *testing.T) {
+	before := NewRoute("/", NewComponent(testComponent{}), WithChildren(
+		NewRoute("a", NewComponent(testComponent{})),
+		NewRoute("c", NewComponent(testComponent{})),
+	)).newNode(nil)
+
+	after := NewRoute("/", NewComponent(testComponent{}), WithChildren(
+		NewRoute("a", NewComponent(testComponent{})),
+		NewRoute("b", NewComponent(testComponent{})),
+		NewRoute("c", NewComponent(testComponent{})),
+	)).newNode(nil)
+
+	assert.Equals(t, before.children[1].id, after.children[2].id)
+	assert.Equals(t, true, validRouteID(after.children[2].id))
+}
+
+func TestRouteMatchPath(t *testing.T) {
+	root := NewRoute("/", NewComponent(testComponent{}), WithChildren(
+		NewRoute("user", NewComponent(testComponent{})),
+		NewRoute("user/{name}/", NewComponent(testComponent{})),
+	)).newNode(nil)
+	user := root.children[0]
+	userDetail := root.children[1]
+
+	assert.Equals(t, true, root.matchPath("/users", "/user/bob/"))
+	assert.Equals(t, false, user.matchPath("/users", "/user"))
+	assert.Equals(t, false, user.matchPath("/user/bob/", "/user"))
+	assert.Equals(t, true, userDetail.matchPath("/user/bob/", "/user/bob/"))
+	assert.Equals(t, false, userDetail.matchPath("/user/alice/", "/user/bob/"))
+	assert.Equals(t, false, userDetail.matchPath("", "/user/bob/"))
+}
+
+func TestRouteBuildURL(t *testing.T) {
+	root := NewRoute("", NewComponent(testComponent{}), WithChildren(
+		NewRoute("/user/{name}/", NewComponent(testComponent{}), WithName("user.detail")),
+		NewRoute("/files/{path...}", NewComponent(testComponent{}), WithName("files")),
+	)).newNode(nil)
+	assert.NoErr(t, root.indexNames())
+
+	u, err := root.names["user.detail"].buildURL("name", "bob smith")
+	assert.NoErr(t, err)
+	assert.Equals(t, "/user/bob%20smith/", u)
+
+	u, err = root.names["files"].buildURL("path", "a/b c")
+	assert.NoErr(t, err)
+	assert.Equals(t, "/files/a/b%20c", u)
+
+	_, err = root.names["user.detail"].buildURL()
+	assert.Err(t, err)
+
+	_, err = root.names["user.detail"].buildURL("name", "bob", "id", "1")
+	assert.Err(t, err)
+}
+
+func TestRouteIndexNames_duplicate(t *testing.T) {
+	root := NewRoute("/", NewComponent(testComponent{}), WithChildren(
+		NewRoute("a", NewComponent(testComponent{}), WithName("page")),
+		NewRoute("b", NewComponent(testComponent{}), WithName("page")),
+	)).newNode(nil)
+
+	assert.Err(t, root.indexNames())
+}
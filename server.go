@@ -1,13 +1,26 @@
 package gong
 
 import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 
+	"github.com/troygilman/gong/internal/bind"
 	"github.com/troygilman/gong/internal/response_writer"
 )
 
+const (
+	defaultShutdownTimeout = 10 * time.Second
+	// defaultMultipartMemory matches the limit used by http.Request.FormFile.
+	defaultMultipartMemory = 32 << 20
+)
+
 // Option is a function type for configuring servers with the options pattern.
 // It takes a Server pointer and returns a modified Server pointer.
 type ServerOption func(*Server) *Server
@@ -21,19 +34,199 @@ func WithErrorHandler(handler ErrorHandler) ServerOption {
 	}
 }
 
+// WithMiddleware adds middleware that wraps every request handled by the server,
+// including handlers registered with Handle.
+func WithMiddleware(middleware ...Middleware) ServerOption {
+	return func(s *Server) *Server {
+		s.middleware = append(s.middleware, middleware...)
+		return s
+	}
+}
+
+// WithActionMiddleware adds middleware that only wraps action requests.
+// It runs after the target route and component have been resolved, so the
+// request context can be passed to helpers such as ComponentID and Request.
+func WithActionMiddleware(middleware ...Middleware) ServerOption {
+	return func(s *Server) *Server {
+		s.actionMiddleware = append(s.actionMiddleware, middleware...)
+		return s
+	}
+}
+
+// WithNotFound sets the component rendered inside the layout when a request
+// matches no route. Routes can override it for their subtree with WithRouteNotFound.
+func WithNotFound(component Component) ServerOption {
+	return func(s *Server) *Server {
+		s.notFound = component
+		return s
+	}
+}
+
+// WithErrorComponent sets the component rendered in place of any View or Action
+// that returns an error. Routes can override it for their subtree with WithRouteErrorComponent.
+func WithErrorComponent(component Component) ServerOption {
+	return func(s *Server) *Server {
+		s.errorComponent = component
+		return s
+	}
+}
+
+// WithDocument sets the function that renders the HTML document shell around
+// full page renders. The default is DefaultDocument.
+func WithDocument(document DocumentFunc) ServerOption {
+	return func(s *Server) *Server {
+		s.document = document
+		return s
+	}
+}
+
+// WithHTMXSource sets the URL the htmx script is loaded from, replacing the
+// htmx build that Gong serves itself.
+func WithHTMXSource(src string) ServerOption {
+	return func(s *Server) *Server {
+		s.htmxScript = script{src: src}
+		return s
+	}
+}
+
+// WithHTMXExtensions adds scripts for htmx extensions, loaded after htmx.
+// Extensions are activated on elements with the hx-ext attribute.
+func WithHTMXExtensions(srcs ...string) ServerOption {
+	return func(s *Server) *Server {
+		for _, src := range srcs {
+			s.extensionScripts = append(s.extensionScripts, script{src: src})
+		}
+		return s
+	}
+}
+
+// WithCSRF configures the CSRF protection of action requests, which is enabled by default.
+func WithCSRF(config CSRFConfig) ServerOption {
+	return func(s *Server) *Server {
+		if config.CookieName == "" {
+			config.CookieName = defaultCSRFCookieName
+		}
+		if config.SameSite == 0 {
+			config.SameSite = http.SameSiteLaxMode
+		}
+		s.csrf = &config
+		return s
+	}
+}
+
+// WithoutCSRF disables the CSRF protection of action requests.
+func WithoutCSRF() ServerOption {
+	return func(s *Server) *Server {
+		s.csrf = nil
+		return s
+	}
+}
+
+// WithSessions configures the sessions returned by Session.
+// By default sessions are kept in a MemoryStore.
+func WithSessions(config SessionConfig) ServerOption {
+	return func(s *Server) *Server {
+		s.sessions = config.withDefaults()
+		return s
+	}
+}
+
+// WithSubscribeAuthorizer sets the function that authorizes subscriptions of
+// WithSubscribe Targets. Without one, a client may subscribe to any topic on
+// a route its guards let it access.
+func WithSubscribeAuthorizer(authorize SubscribeAuthorizer) ServerOption {
+	return func(s *Server) *Server {
+		s.authorizeSubscribe = authorize
+		return s
+	}
+}
+
+// WithMultipartMemory sets how many bytes of a multipart form Bind keeps in
+// memory. The rest of the uploaded files is stored in temporary files, which
+// are removed when the request is done. The default is 32 MB.
+func WithMultipartMemory(bytes int64) ServerOption {
+	return func(s *Server) *Server {
+		s.multipartMemory = bytes
+		return s
+	}
+}
+
+// BindLimits bounds the form data accepted by Bind. Requests exceeding them
+// are rejected with a *BindLimitError, which maps to 400 (Bad Request).
+type BindLimits = bind.Limits
+
+// WithBindLimits sets the limits enforced by Bind. Fields left zero keep their
+// default from bind's DefaultLimits; a negative field removes that limit.
+func WithBindLimits(limits BindLimits) ServerOption {
+	return func(s *Server) *Server {
+		defaults := bind.DefaultLimits
+		s.bindLimits = BindLimits{
+			MaxSliceIndex: cmp.Or(limits.MaxSliceIndex, defaults.MaxSliceIndex),
+			MaxDepth:      cmp.Or(limits.MaxDepth, defaults.MaxDepth),
+			MaxKeys:       cmp.Or(limits.MaxKeys, defaults.MaxKeys),
+			MaxFields:     cmp.Or(limits.MaxFields, defaults.MaxFields),
+			MaxElements:   cmp.Or(limits.MaxElements, defaults.MaxElements),
+		}
+		return s
+	}
+}
+
+// WithShutdownTimeout sets how long RunContext waits for in-flight requests
+// to finish after its context is cancelled before forcing connections closed.
+func WithShutdownTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) *Server {
+		s.shutdownTimeout = timeout
+		return s
+	}
+}
+
 // Server is the main framework instance that handles routing and request processing.
 // It implements the http.Handler interface and manages the application's routes.
 type Server struct {
-	mux          *http.ServeMux
-	routes       []Route
-	errorHandler ErrorHandler
+	mux                *http.ServeMux
+	routes             []Route
+	errorHandler       ErrorHandler
+	shutdownTimeout    time.Duration
+	middleware         []Middleware
+	actionMiddleware   []Middleware
+	notFound           Component
+	errorComponent     Component
+	document           DocumentFunc
+	htmxScript         script
+	extensionScripts   []script
+	patterns           map[string]bool
+	broker             *sseBroker
+	authorizeSubscribe SubscribeAuthorizer
+	csrf               *CSRFConfig
+	sessions           SessionConfig
+	multipartMemory    int64
+	bindLimits         BindLimits
+
+	buildOnce sync.Once
+	root      *routeNode
+	handler   http.Handler
+	buildErr  error
 }
 
 // New creates a new Server instance.
 // It accepts optional configurations via the Option pattern.
 func NewServer(opts ...ServerOption) *Server {
 	s := &Server{
-		mux: http.NewServeMux(),
+		mux:              http.NewServeMux(),
+		shutdownTimeout:  defaultShutdownTimeout,
+		multipartMemory:  defaultMultipartMemory,
+		bindLimits:       bind.DefaultLimits,
+		notFound:         NewComponent(notFoundComponent{}),
+		document:         DefaultDocument,
+		htmxScript:       defaultHTMXScript(),
+		extensionScripts: defaultExtensionScripts(),
+		patterns:         make(map[string]bool),
+		broker:           newSSEBroker(),
+		csrf: &CSRFConfig{
+			CookieName: defaultCSRFCookieName,
+			SameSite:   http.SameSiteLaxMode,
+		},
+		sessions: SessionConfig{}.withDefaults(),
 	}
 	for _, opt := range opts {
 		s = opt(s)
@@ -43,76 +236,305 @@ func NewServer(opts ...ServerOption) *Server {
 
 // Handle registers a handler for the given pattern in the server's HTTP mux.
 func (svr *Server) Handle(pattern string, handler http.Handler) {
+	svr.patterns[pattern] = true
 	svr.mux.Handle(pattern, handler)
 }
 
 // Route registers a route with the server.
-// The route will be set up with appropriate handlers when the server runs.
+// The route will be set up with appropriate handlers when the server is built.
+// Routes registered after the first call to Build are ignored.
 func (svr *Server) Route(route Route) {
 	svr.routes = append(svr.routes, route)
 }
 
+// Build constructs the route tree and registers its handlers with the server's mux.
+// The tree is built only once; subsequent calls return the same handler and error.
+// The returned handler can be mounted in any http.Server or httptest.Server.
+func (svr *Server) Build() (http.Handler, error) {
+	svr.buildOnce.Do(func() {
+		index := indexComponent{
+			document: svr.document,
+			scripts:  append([]script{svr.htmxScript}, svr.extensionScripts...),
+		}
+		root := NewRoute("", NewComponent(index),
+			WithChildren(svr.routes...),
+			WithRouteNotFound(svr.notFound),
+			WithRouteErrorComponent(svr.errorComponent),
+		).newNode(nil)
+		if svr.buildErr = root.validate(); svr.buildErr != nil {
+			return
+		}
+		if svr.buildErr = root.indexNames(); svr.buildErr != nil {
+			return
+		}
+		if svr.buildErr = svr.registerRoutes(root); svr.buildErr != nil {
+			return
+		}
+		svr.root = root
+		svr.handler = chainMiddleware(svr.mux, svr.middleware)
+	})
+	return svr.handler, svr.buildErr
+}
+
+// registerRoutes registers the handlers of the route tree and Gong's own
+// endpoints with the server's mux. The mux panics when a pattern is invalid or
+// conflicts with one already registered, so such a panic is returned as an error.
+func (svr *Server) registerRoutes(root *routeNode) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("gong: %v", v)
+		}
+	}()
+	svr.Handle(staticPath, staticHandler())
+	svr.Handle(ssePath, svr.subscribeHandler(root))
+	for _, node := range root.children {
+		svr.setupRoute(root, node)
+	}
+	if !svr.patterns["/"] {
+		svr.Handle("/", svr.routeHandler(root, root))
+	}
+	return nil
+}
+
+// ServeHTTP implements the http.Handler interface.
+// The route tree is built on the first request if Build has not been called.
+func (svr *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	handler, err := svr.Build()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
+
 // Run starts the server and begins listening for HTTP requests on the specified address.
 // This method blocks until the server is stopped or encounters an error.
 func (svr *Server) Run(addr string) error {
-	root := NewRoute("", NewComponent(indexComponent{}), WithChildren(svr.routes...)).newNode(nil, "")
-	for _, node := range root.children {
-		svr.setupRoute(root, node)
+	return svr.RunContext(context.Background(), addr)
+}
+
+// RunContext starts the server on the specified address and blocks until ctx is
+// cancelled or the server encounters an error. When ctx is cancelled the server
+// stops accepting new connections and waits up to the shutdown timeout for
+// in-flight requests to complete. Event streams of WithSubscribe Targets are
+// closed when the shutdown starts.
+func (svr *Server) RunContext(ctx context.Context, addr string) error {
+	handler, err := svr.Build()
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: handler,
 	}
-	return http.ListenAndServe(addr, svr.mux)
+	httpServer.RegisterOnShutdown(svr.broker.close)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), svr.shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
 }
 
 func (svr *Server) setupRoute(root *routeNode, node *routeNode) {
 	log.Printf("Route=%s\n", node.path)
 
-	svr.mux.Handle(node.path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var (
-			writer      = response_writer.NewResponseWriter(w)
-			requestType = r.Header.Get(HeaderGongRequestType)
-		)
+	svr.Handle(node.path, svr.routeHandler(root, node))
+
+	for _, child := range node.children {
+		svr.setupRoute(root, child)
+	}
+}
+
+// routeHandler returns the handler registered for a route node.
+// It resolves the route that will handle the request, wraps the render step in
+// that route's middleware, and additionally in the action middleware for action requests.
+// Errors and panics are turned into error responses by handleError.
+func (svr *Server) routeHandler(root *routeNode, node *routeNode) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestType := r.Header.Get(HeaderGongRequestType)
 
 		gCtx := gongContext{
-			Request:      r,
-			Writer:       writer,
-			Action:       requestType == GongRequestTypeAction,
-			Link:         requestType == GongRequestTypeLink,
-			RouteID:      node.id,
-			ComponentID:  r.Header.Get(HeaderGongComponentID),
-			RenderedPath: getCurrentUrl(r),
-			ErrorHandler: svr.errorHandler,
-		}
-
-		switch requestType {
-		case GongRequestTypeAction:
-			gCtx.Node = root.find(r.Header.Get(HeaderGongRouteID))
-		case GongRequestTypeLink:
-			gCtx.Node = root
-		default:
+			Request:         r,
+			Action:          requestType == GongRequestTypeAction,
+			Link:            requestType == GongRequestTypeLink,
+			RouteID:         node.id,
+			ComponentID:     r.Header.Get(HeaderGongComponentID),
+			RenderedPath:    getCurrentUrl(r),
+			FieldErrors:     ValidationErrors{},
+			MultipartMemory: svr.multipartMemory,
+			BindLimits:      svr.bindLimits,
+			ErrorHandler:    svr.errorHandler,
+		}
+
+		defer func() {
+			if v := recover(); v != nil {
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				svr.handleError(w, r, gCtx, fmt.Errorf("gong: panic: %v", v))
+			}
+		}()
+
+		target, err := resolveRoute(root, node, gCtx)
+		if err != nil {
+			svr.handleError(w, r, gCtx, err)
+			return
+		}
+
+		if svr.csrf != nil {
+			token, fromCookie, err := svr.csrf.csrfToken(w, r)
+			if err != nil {
+				svr.handleError(w, r, gCtx, err)
+				return
+			}
+			if gCtx.Action {
+				if err := svr.csrf.verify(r, token, fromCookie); err != nil {
+					svr.handleError(w, r, gCtx, err)
+					return
+				}
+			}
+			gCtx.CSRFToken = token
+		}
+		gCtx.Session = newSession(svr.sessions, r, sessionErrorHandler(gCtx))
+		if gCtx.Action {
+			gCtx.Node = target
+		} else {
 			gCtx.Node = root
+			if node == root || !matchPatternExact(node.path, r.URL.EscapedPath()) {
+				gCtx.NotFound = node.notFoundHost()
+			}
 		}
 
 		log.Println("RequestPath:", r.URL.Path, "RouteID:", gCtx.RouteID)
 
-		if gCtx.Node == nil {
-			panic("route is nil")
+		var handler http.Handler = http.HandlerFunc(svr.renderRoute)
+		if len(target.guards) > 0 {
+			handler = svr.guardHandler(handler, target.guards)
 		}
+		if gCtx.Action {
+			handler = chainMiddleware(handler, svr.actionMiddleware)
+		}
+		handler = chainMiddleware(handler, target.middleware)
+
+		handler.ServeHTTP(w, r.WithContext(setContext(r.Context(), gCtx)))
+	})
+}
 
-		if err := render(r.Context(), gCtx, writer, gCtx.Node); err != nil {
-			panic(err)
+// resolveRoute returns the route node that handles the request.
+// Page and link requests are handled by the node registered for the URL, while
+// action requests are handled by the node identified by the Gong-Route-ID header
+// and must target a component that exists in that route.
+func resolveRoute(root *routeNode, node *routeNode, gCtx gongContext) (*routeNode, error) {
+	if !gCtx.Action {
+		return node, nil
+	}
+	routeID := gCtx.Request.Header.Get(HeaderGongRouteID)
+	if routeID == "" || gCtx.ComponentID == "" {
+		return nil, fmt.Errorf("%w: action requests require %s and %s", ErrBadGongHeaders, HeaderGongRouteID, HeaderGongComponentID)
+	}
+	return findTarget(root, routeID, gCtx.ComponentID)
+}
+
+// findTarget returns the route node with the given route ID after checking
+// that its component tree contains a component with the given ID path.
+func findTarget(root *routeNode, routeID string, componentID string) (*routeNode, error) {
+	if !validRouteID(routeID) {
+		return nil, fmt.Errorf("%w: malformed route id %q", ErrBadGongHeaders, routeID)
+	}
+	target := root.find(routeID)
+	if target == nil {
+		return nil, fmt.Errorf("%w: no route with id %q", ErrRouteNotFound, routeID)
+	}
+	if _, ok := target.route.component.Find(componentID); !ok {
+		return nil, fmt.Errorf("%w: no component with id %q in route %q", ErrComponentNotFound, componentID, target.path)
+	}
+	return target, nil
+}
+
+// renderRoute renders the route node stored in the request context into a
+// buffered writer and flushes it to the client.
+func (svr *Server) renderRoute(w http.ResponseWriter, r *http.Request) {
+	writer := response_writer.NewResponseWriter(w)
+
+	gCtx := getContext(r.Context())
+	gCtx.Request = r
+	gCtx.Writer = writer
+	defer func() {
+		// Requests derived with WithContext are not cleaned up by net/http,
+		// so remove the temporary files of forms parsed by Bind here.
+		if r.MultipartForm != nil {
+			_ = r.MultipartForm.RemoveAll()
 		}
+	}()
+	writer.BeforeFlush(func() error {
+		return gCtx.Session.save(writer)
+	})
 
-		if err := writer.Flush(); err != nil {
-			panic(err)
+	if gCtx.NotFound != nil && !isHTMXRequest(r) {
+		writer.WriteHeader(http.StatusNotFound)
+	}
+
+	if err := render(r.Context(), gCtx, writer, gCtx.Node); err != nil {
+		svr.handleError(w, r, gCtx, err)
+		return
+	}
+
+	if err := writer.Flush(); err != nil {
+		if !writer.Flushed() {
+			svr.handleError(w, r, gCtx, err)
+			return
 		}
-	}))
+		log.Println("gong: failed to write response:", err)
+	}
+}
 
-	for _, child := range node.children {
-		svr.setupRoute(root, child)
+// handleError discards any buffered output, including headers such as
+// HX-Trigger set by the failed View or Action, and responds with the status code
+// mapped from err by StatusCode. The server's ErrorHandler, if any, is called
+// before the response is flushed so it can adjust headers or redirect.
+func (svr *Server) handleError(w http.ResponseWriter, r *http.Request, gCtx gongContext, err error) {
+	writer := response_writer.NewResponseWriter(w)
+	writer.WriteHeader(StatusCode(err))
+
+	gCtx.Request = r
+	gCtx.Writer = writer
+
+	if svr.errorHandler != nil {
+		svr.errorHandler(setContext(r.Context(), gCtx), err)
+	} else {
+		log.Println(err)
+	}
+
+	if writer.Len() == 0 {
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writer.Header().Set("X-Content-Type-Options", "nosniff")
+		_, _ = writer.Write([]byte(http.StatusText(writer.StatusCode())))
+	}
+
+	if err := writer.Flush(); err != nil {
+		log.Println("gong: failed to write error response:", err)
 	}
 }
 
 func getCurrentUrl(r *http.Request) string {
-	currentUrl := r.Header.Get("Hx-Current-Url")
+	currentUrl := r.Header.Get(HeaderHXCurrentURL)
 	u, err := url.Parse(currentUrl)
 	if err != nil {
 		return ""
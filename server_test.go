@@ -0,0 +1,564 @@
+package gong
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/troygilman/gong/internal/assert"
+)
+
+func TestServerBuild(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: testTemplComponent{text: "view"},
+	})))
+
+	handler, err := svr.Build()
+	assert.NoErr(t, err)
+
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/")
+	assert.NoErr(t, err)
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	assert.NoErr(t, err)
+	assert.Equals(t, http.StatusOK, res.StatusCode)
+	assert.Equals(t, true, strings.Contains(string(body), "view"))
+}
+
+func TestServerServeHTTP(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: testTemplComponent{text: "view"},
+	})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), "view"))
+}
+
+func TestServerRunContext(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- svr.RunContext(ctx, "127.0.0.1:0")
+	}()
+
+	cancel()
+	assert.NoErr(t, <-errCh)
+}
+
+func TestServerRunContext_sseSubscriber(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoErr(t, err)
+	addr := listener.Addr().String()
+	assert.NoErr(t, listener.Close())
+
+	svr := NewServer(WithShutdownTimeout(5 * time.Second))
+	svr.Route(NewRoute("/", NewComponent(testComponent{})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- svr.RunContext(ctx, addr)
+	}()
+
+	var res *http.Response
+	for {
+		res, err = http.Get("http://" + addr + testSubscribeURL("orders"))
+		if err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer res.Body.Close()
+	for !svr.broker.hasSubscribers("orders") {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		assert.NoErr(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunContext did not return while an event stream was open")
+	}
+}
+
+func TestServerMiddleware(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	svr := NewServer(WithMiddleware(record("server")))
+	svr.Route(NewRoute("/", NewComponent(testComponent{}),
+		WithRouteMiddleware(record("parent")),
+		WithChildren(
+			NewRoute("child", NewComponent(testComponent{}), WithRouteMiddleware(record("child"))),
+		),
+	))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/child"))
+
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, []string{"server", "parent", "child"}, calls)
+}
+
+func TestServerActionMiddleware(t *testing.T) {
+	var componentID string
+	svr := NewServer(WithActionMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			componentID = ComponentID(r.Context())
+			next.ServeHTTP(w, r)
+		})
+	}))
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		action: testTemplComponent{text: "action"},
+	}, WithComponentID("mock"))))
+
+	r := newTestActionRequest(routeSegment("/"), "mock")
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, "action", rec.Body.String())
+	assert.Equals(t, "gong_"+routeSegment("/")+"_mock", componentID)
+}
+
+func TestServerActionErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		routeID     string
+		componentID string
+		status      int
+		err         error
+	}{
+		{
+			name:        "missing headers",
+			routeID:     "",
+			componentID: "",
+			status:      http.StatusBadRequest,
+			err:         ErrBadGongHeaders,
+		},
+		{
+			name:        "malformed route id",
+			routeID:     "9",
+			componentID: "mock",
+			status:      http.StatusBadRequest,
+			err:         ErrBadGongHeaders,
+		},
+		{
+			name:        "unknown route",
+			routeID:     "ffffffff",
+			componentID: "mock",
+			status:      http.StatusNotFound,
+			err:         ErrRouteNotFound,
+		},
+		{
+			name:        "stale component",
+			routeID:     routeSegment("/"),
+			componentID: "stale",
+			status:      http.StatusNotFound,
+			err:         ErrComponentNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var handled error
+			svr := NewServer(WithErrorHandler(func(ctx context.Context, err error) {
+				handled = err
+			}))
+			svr.Route(NewRoute("/", NewComponent(testComponent{}, WithComponentID("mock"))))
+
+			r := newTestActionRequest(test.routeID, test.componentID)
+
+			rec := httptest.NewRecorder()
+			svr.ServeHTTP(rec, r)
+
+			assert.Equals(t, test.status, rec.Code)
+			assert.Equals(t, true, errors.Is(handled, test.err))
+		})
+	}
+}
+
+func TestServerRecoversPanic(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			panic("boom")
+		}),
+	})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	assert.Equals(t, http.StatusInternalServerError, rec.Code)
+	assert.Equals(t, http.StatusText(http.StatusInternalServerError), rec.Body.String())
+}
+
+func TestServerBuild_duplicateRoutes(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{}), WithChildren(
+		NewRoute("users", NewComponent(testComponent{})),
+		NewRoute("users", NewComponent(testComponent{})),
+	)))
+
+	_, err := svr.Build()
+	assert.Err(t, err)
+}
+
+func TestServerBuild_conflictingPatterns(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{}), WithChildren(
+		NewRoute("a", NewComponent(testComponent{})),
+	)))
+	svr.Route(NewRoute("/a", NewComponent(testComponent{})))
+
+	_, err := svr.Build()
+	assert.Err(t, err)
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+	assert.Equals(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestServerLink_namedRoute(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: Link("user.detail", WithParams("name", "bob")),
+	}), WithChildren(
+		NewRoute("user/{name}/", NewComponent(testComponent{}), WithName("user.detail")),
+	)))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), `href="/user/bob/"`))
+}
+
+type testLayoutComponent struct{}
+
+func (c testLayoutComponent) View() templ.Component {
+	return RenderFunc(func(ctx context.Context, w io.Writer) error {
+		if _, err := io.WriteString(w, "layout:"); err != nil {
+			return err
+		}
+		return Outlet().Render(ctx, w)
+	})
+}
+
+func TestServerLink_unknownRouteName(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: Link("user.detial", WithParams("name", "bob")),
+	}), WithChildren(
+		NewRoute("user/{name}/", NewComponent(testComponent{}), WithName("user.detail")),
+	)))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	assert.Equals(t, http.StatusInternalServerError, rec.Code)
+	assert.Equals(t, false, strings.Contains(rec.Body.String(), `href="user.detial"`))
+}
+
+func TestServerLink_relativePath(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: Link("./settings"),
+	})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), `href="./settings"`))
+}
+
+func TestServerNotFound(t *testing.T) {
+	svr := NewServer(WithNotFound(NewComponent(testComponent{
+		view: testTemplComponent{text: "server not found"},
+	})))
+	svr.Route(NewRoute("/", NewComponent(testLayoutComponent{}), WithChildren(
+		NewRoute("users", NewComponent(testComponent{
+			view: testTemplComponent{text: "users"},
+		})),
+		NewRoute("admin/", NewComponent(testLayoutComponent{}),
+			WithRouteNotFound(NewComponent(testComponent{
+				view: testTemplComponent{text: "admin not found"},
+			})),
+			WithChildren(
+				NewRoute("settings", NewComponent(testComponent{})),
+			),
+		),
+	)))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/nope"))
+	assert.Equals(t, http.StatusNotFound, rec.Code)
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), "layout:<div"))
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), "server not found"))
+
+	rec = httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/admin/nope"))
+	assert.Equals(t, http.StatusNotFound, rec.Code)
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), "admin not found"))
+
+	rec = httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/users"))
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), "users"))
+}
+
+func TestServerNotFound_noRootRoute(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/users", NewComponent(testComponent{})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	assert.Equals(t, http.StatusNotFound, rec.Code)
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), "404 page not found"))
+}
+
+func TestServerErrorComponent(t *testing.T) {
+	svr := NewServer(WithErrorComponent(NewComponent(testComponent{
+		view: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, "error: "+RenderError(ctx).Error())
+			return err
+		}),
+	})))
+	svr.Route(NewRoute("/", NewComponent(testLayoutComponent{}), WithChildren(
+		NewRoute("broken", NewComponent(testComponent{
+			view: Error(errors.New("broken view")),
+		})),
+	)))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/broken"))
+
+	assert.Equals(t, http.StatusInternalServerError, rec.Code)
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), "layout:<div"))
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), "error: broken view"))
+}
+
+type testHeadComponent struct {
+	testComponent
+	head string
+}
+
+func (c testHeadComponent) Head() templ.Component {
+	return testTemplComponent{text: c.head}
+}
+
+func TestServerDocument(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testHeadComponent{
+		testComponent: testComponent{view: Outlet()},
+		head:          `<title>Parent</title><meta name="description" content="parent"><link rel="icon" href="/icon.png">`,
+	}), WithChildren(
+		NewRoute("child", NewComponent(testHeadComponent{head: `<title>Child</title>`})),
+	)))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/child"))
+
+	body := rec.Body.String()
+	assert.Equals(t, true, strings.HasPrefix(body, `<!doctype html><html lang="en"><head><meta id="gong_head_meta-charset" charset="utf-8"><title id="gong_head_title">Child</title>`))
+	assert.Equals(t, 1, strings.Count(body, "<title"))
+	assert.Equals(t, true, strings.Contains(body, `<meta id="gong_head_meta-name-description" name="description" content="parent"><link rel="icon" href="/icon.png"></head>`))
+}
+
+func TestServerDocument_linkHeadOOB(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{view: Outlet()}), WithChildren(
+		NewRoute("a", NewComponent(testHeadComponent{head: `<title>A</title>`})),
+		NewRoute("b", NewComponent(testHeadComponent{head: `<title>B</title>`})),
+	)))
+
+	r := newTestRequest(http.MethodGet, "/b")
+	r.Header.Set(HeaderGongRequestType, GongRequestTypeLink)
+	r.Header.Set("Hx-Request", "true")
+	r.Header.Set("Hx-Current-Url", "http://localhost/a")
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	body := rec.Body.String()
+	assert.Equals(t, true, strings.Contains(body, `<title hx-swap-oob="outerHTML" id="gong_head_title">B</title>`))
+	assert.Equals(t, true, strings.Contains(body, `hx-swap-oob="innerHTML"`))
+}
+
+func TestServerLink_fromChildRoute(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{view: Outlet()}), WithChildren(
+		NewRoute("user", NewComponent(testComponent{view: testTemplComponent{text: "users"}})),
+		NewRoute("user/{name}/", NewComponent(testComponent{view: testTemplComponent{text: "detail"}})),
+	)))
+
+	r := newTestRequest(http.MethodGet, "/user")
+	r.Header.Set(HeaderGongRequestType, GongRequestTypeLink)
+	r.Header.Set("Hx-Request", "true")
+	r.Header.Set("Hx-Current-Url", "http://localhost/user/bob/")
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), "users"))
+}
+
+func TestServerDocument_custom(t *testing.T) {
+	svr := NewServer(WithDocument(func(head, body templ.Component) templ.Component {
+		return RenderFunc(func(ctx context.Context, w io.Writer) error {
+			if _, err := io.WriteString(w, "<custom>"); err != nil {
+				return err
+			}
+			return body.Render(ctx, w)
+		})
+	}))
+	svr.Route(NewRoute("/", NewComponent(testComponent{})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	assert.Equals(t, true, strings.HasPrefix(rec.Body.String(), "<custom><div"))
+}
+
+func TestServerStatic(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{})))
+
+	for _, file := range []string{htmxFile, sseExtFile} {
+		rec := httptest.NewRecorder()
+		svr.ServeHTTP(rec, newTestRequest(http.MethodGet, staticPath+file))
+
+		assert.Equals(t, http.StatusOK, rec.Code)
+		assert.Equals(t, true, strings.Contains(rec.Header().Get("Content-Type"), "javascript"))
+		assert.Equals(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+		assert.Equals(t, true, rec.Body.Len() > 0)
+		if file == htmxFile {
+			sum := sha512.Sum384(rec.Body.Bytes())
+			assert.Equals(t, htmxIntegrity, "sha384-"+base64.StdEncoding.EncodeToString(sum[:]))
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), `src="`+staticPath+htmxFile+`"`))
+}
+
+func TestServerHTMXSource(t *testing.T) {
+	svr := NewServer(
+		WithHTMXSource("/assets/htmx.js"),
+		WithHTMXExtensions("/assets/sse.js"),
+	)
+	svr.Route(NewRoute("/", NewComponent(testComponent{})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	body := rec.Body.String()
+	assert.Equals(t, true, strings.Contains(body, `<script src="/assets/htmx.js"></script>`))
+	assert.Equals(t, true, strings.Contains(body, `<script src="/assets/sse.js"></script>`))
+	assert.Equals(t, false, strings.Contains(body, staticPath+htmxFile))
+}
+
+func TestServerCSRF(t *testing.T) {
+	tests := []struct {
+		name   string
+		cookie string
+		header string
+		status int
+	}{
+		{name: "valid token", cookie: testCSRFToken, header: testCSRFToken, status: http.StatusOK},
+		{name: "missing cookie", cookie: "", header: testCSRFToken, status: http.StatusForbidden},
+		{name: "missing header", cookie: testCSRFToken, header: "", status: http.StatusForbidden},
+		{name: "mismatched token", cookie: testCSRFToken, header: "dGVzdC1jc3JmLXRva2VuLXRlc3QtY3NyZi10b2tlbjE", status: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svr := NewServer()
+			svr.Route(NewRoute("/", NewComponent(testComponent{
+				action: testTemplComponent{text: "action"},
+			}, WithComponentID("mock"))))
+
+			r := newTestRequest(http.MethodPost, "/")
+			r.Header.Set(HeaderGongRequestType, GongRequestTypeAction)
+			r.Header.Set(HeaderGongRouteID, routeSegment("/"))
+			r.Header.Set(HeaderGongComponentID, "mock")
+			if test.header != "" {
+				r.Header.Set(HeaderGongCSRFToken, test.header)
+			}
+			if test.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: defaultCSRFCookieName, Value: test.cookie})
+			}
+
+			rec := httptest.NewRecorder()
+			svr.ServeHTTP(rec, r)
+
+			assert.Equals(t, test.status, rec.Code)
+		})
+	}
+}
+
+func TestServerCSRF_issuesToken(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{view: Target()})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	cookies := rec.Result().Cookies()
+	assert.Equals(t, 1, len(cookies))
+	assert.Equals(t, defaultCSRFCookieName, cookies[0].Name)
+	assert.Equals(t, true, cookies[0].HttpOnly)
+	assert.Equals(t, true, strings.Contains(rec.Body.String(), cookies[0].Value))
+}
+
+func TestServerCSRF_disabled(t *testing.T) {
+	svr := NewServer(WithoutCSRF())
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		action: testTemplComponent{text: "action"},
+	}, WithComponentID("mock"))))
+
+	r := newTestRequest(http.MethodPost, "/")
+	r.Header.Set(HeaderGongRequestType, GongRequestTypeAction)
+	r.Header.Set(HeaderGongRouteID, routeSegment("/"))
+	r.Header.Set(HeaderGongComponentID, "mock")
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, 0, len(rec.Result().Cookies()))
+}
@@ -0,0 +1,300 @@
+package gong
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"log"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSessionCookieName = "gong_session"
+	defaultSessionMaxAge     = 24 * time.Hour
+	flashKeyPrefix           = "_gong_flash:"
+	// memoryStoreSweepInterval is how often a MemoryStore removes expired sessions.
+	memoryStoreSweepInterval = time.Minute
+)
+
+// SessionStore loads and saves the values of sessions.
+// Each session is identified by the value of the session cookie, which the
+// store chooses when the session is saved: server side stores return an ID
+// that references the values, while cookie stores return the encoded values.
+// Implementations must be safe for concurrent use.
+type SessionStore interface {
+	// Load returns the values of the session identified by the cookie value.
+	// It returns an empty map if the session does not exist or has expired.
+	Load(ctx context.Context, cookie string) (map[string]any, error)
+	// Save stores the values of the session identified by the cookie value
+	// and returns the new cookie value. The cookie value is empty for new
+	// sessions and for sessions renewed with SessionData.Renew, which must be
+	// given a new, unpredictable ID. The session should expire after maxAge.
+	Save(ctx context.Context, cookie string, values map[string]any, maxAge time.Duration) (string, error)
+	// Delete removes the session identified by the cookie value.
+	Delete(ctx context.Context, cookie string) error
+}
+
+// SessionConfig configures the sessions returned by Session.
+type SessionConfig struct {
+	// Store holds the session values. Defaults to a MemoryStore.
+	Store SessionStore
+	// CookieName is the name of the session cookie. Defaults to "gong_session".
+	CookieName string
+	// MaxAge is how long a session lives after it was last modified. Defaults to 24 hours.
+	MaxAge time.Duration
+	// Secure restricts the cookie to HTTPS connections.
+	Secure bool
+	// SameSite sets the SameSite attribute of the cookie. Defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+}
+
+func (config SessionConfig) withDefaults() SessionConfig {
+	if config.Store == nil {
+		config.Store = NewMemoryStore()
+	}
+	if config.CookieName == "" {
+		config.CookieName = defaultSessionCookieName
+	}
+	if config.MaxAge == 0 {
+		config.MaxAge = defaultSessionMaxAge
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	return config
+}
+
+// SessionData holds the values of the current user's session.
+// The values are loaded from the store the first time they are accessed and
+// saved when the response is flushed, if they were modified.
+type SessionData struct {
+	config   SessionConfig
+	request  *http.Request
+	cookie   string
+	values   map[string]any
+	loaded   bool
+	modified bool
+	renewed  bool
+	// handleError reports load errors, which leave the session empty.
+	handleError func(error)
+}
+
+// newSession returns the session of the request, backed by the configured store.
+func newSession(config SessionConfig, r *http.Request, handleError func(error)) *SessionData {
+	session := &SessionData{
+		config:      config,
+		request:     r,
+		handleError: handleError,
+	}
+	if cookie, err := r.Cookie(config.CookieName); err == nil {
+		session.cookie = cookie.Value
+	}
+	return session
+}
+
+// Session returns the session of the current request.
+// Values stored in the session must be supported by the server's SessionStore;
+// the CookieStore encodes them with encoding/gob, so custom types must be
+// registered with gob.Register.
+func Session(ctx context.Context) *SessionData {
+	gCtx := getContext(ctx)
+	if gCtx.Session == nil {
+		return &SessionData{values: map[string]any{}, loaded: true}
+	}
+	return gCtx.Session
+}
+
+// Get returns the value stored under key, or nil if there is none.
+func (s *SessionData) Get(key string) any {
+	return s.load()[key]
+}
+
+// Set stores value under key.
+func (s *SessionData) Set(key string, value any) {
+	s.load()[key] = value
+	s.modified = true
+}
+
+// Delete removes the value stored under key.
+func (s *SessionData) Delete(key string) {
+	values := s.load()
+	if _, ok := values[key]; ok {
+		delete(values, key)
+		s.modified = true
+	}
+}
+
+// Flash stores a value under key that is removed from the session the first
+// time it is read with GetFlash, typically on the next request.
+func (s *SessionData) Flash(key string, value any) {
+	s.Set(flashKeyPrefix+key, value)
+}
+
+// GetFlash returns the flash value stored under key and removes it from the
+// session, or returns nil if there is none.
+func (s *SessionData) GetFlash(key string) any {
+	value := s.Get(flashKeyPrefix + key)
+	s.Delete(flashKeyPrefix + key)
+	return value
+}
+
+// Renew moves the session to a new ID when the response is sent and deletes
+// the session stored under the old ID, keeping its values. Call it when the
+// user's privileges change, such as on login and logout, so that a session ID
+// planted in the user's browser before the change cannot be used after it.
+func (s *SessionData) Renew() {
+	s.load()
+	s.renewed = true
+	s.modified = true
+}
+
+func (s *SessionData) load() map[string]any {
+	if s.loaded {
+		return s.values
+	}
+	s.loaded = true
+	s.values = map[string]any{}
+	if s.cookie == "" {
+		return s.values
+	}
+	values, err := s.config.Store.Load(s.request.Context(), s.cookie)
+	if err != nil {
+		s.handleError(err)
+		return s.values
+	}
+	if values != nil {
+		s.values = values
+	}
+	return s.values
+}
+
+// save stores the session if it was modified and sets or expires the session cookie.
+// A renewed session is deleted from the store and saved again under a new ID.
+func (s *SessionData) save(w http.ResponseWriter) error {
+	if !s.modified {
+		return nil
+	}
+	ctx := s.request.Context()
+	cookie := &http.Cookie{
+		Name:     s.config.CookieName,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   s.config.Secure,
+		SameSite: s.config.SameSite,
+	}
+	if s.cookie != "" && (s.renewed || len(s.values) == 0) {
+		if err := s.config.Store.Delete(ctx, s.cookie); err != nil {
+			return err
+		}
+		if len(s.values) == 0 {
+			cookie.MaxAge = -1
+		}
+		s.cookie = ""
+	}
+	if len(s.values) > 0 {
+		value, err := s.config.Store.Save(ctx, s.cookie, s.values, s.config.MaxAge)
+		if err != nil {
+			return err
+		}
+		s.cookie = value
+		cookie.Value = value
+		cookie.MaxAge = int(s.config.MaxAge / time.Second)
+	}
+	if cookie.MaxAge != 0 {
+		http.SetCookie(w, cookie)
+	}
+	s.modified = false
+	s.renewed = false
+	return nil
+}
+
+// MemoryStore is a SessionStore that keeps sessions in memory.
+// Sessions are lost when the server restarts and are not shared between
+// server instances. Expired sessions are removed by a sweep that runs at most
+// once a minute, when a session is saved.
+type MemoryStore struct {
+	mu        sync.Mutex
+	sessions  map[string]memorySession
+	nextSweep time.Time
+}
+
+type memorySession struct {
+	values  map[string]any
+	expires time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions:  make(map[string]memorySession),
+		nextSweep: time.Now().Add(memoryStoreSweepInterval),
+	}
+}
+
+// Load returns a copy of the values of the session with the given ID.
+func (store *MemoryStore) Load(ctx context.Context, id string) (map[string]any, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	session, ok := store.sessions[id]
+	if !ok || time.Now().After(session.expires) {
+		return map[string]any{}, nil
+	}
+	return maps.Clone(session.values), nil
+}
+
+// Save stores a copy of the values under the given ID, or under a new random
+// ID if the session does not exist.
+func (store *MemoryStore) Save(ctx context.Context, id string, values map[string]any, maxAge time.Duration) (string, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	now := time.Now()
+	if now.After(store.nextSweep) {
+		store.sweep(now)
+	}
+	if _, ok := store.sessions[id]; !ok {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		id = base64.RawURLEncoding.EncodeToString(b)
+	}
+	store.sessions[id] = memorySession{
+		values:  maps.Clone(values),
+		expires: now.Add(maxAge),
+	}
+	return id, nil
+}
+
+// sweep removes the sessions that expired before now and schedules the next sweep.
+// The caller must hold store.mu.
+func (store *MemoryStore) sweep(now time.Time) {
+	for id, session := range store.sessions {
+		if now.After(session.expires) {
+			delete(store.sessions, id)
+		}
+	}
+	store.nextSweep = now.Add(memoryStoreSweepInterval)
+}
+
+// Delete removes the session with the given ID.
+func (store *MemoryStore) Delete(ctx context.Context, id string) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	delete(store.sessions, id)
+	return nil
+}
+
+// sessionErrorHandler returns the function that reports session load errors
+// for a request, which is the server's ErrorHandler if one is set.
+func sessionErrorHandler(gCtx gongContext) func(error) {
+	return func(err error) {
+		if gCtx.ErrorHandler != nil {
+			gCtx.ErrorHandler(setContext(gCtx.Request.Context(), gCtx), err)
+			return
+		}
+		log.Println("gong: failed to load session:", err)
+	}
+}
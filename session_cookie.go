@@ -0,0 +1,94 @@
+package gong
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxCookieSize is the largest cookie value browsers are guaranteed to store.
+const maxCookieSize = 4096
+
+// CookieStore is a SessionStore that keeps the session values in the session
+// cookie itself, encoded with encoding/gob and encrypted and authenticated
+// with AES-GCM. It needs no server side state, but the encoded values must
+// fit in a single cookie.
+type CookieStore struct {
+	aead cipher.AEAD
+}
+
+type cookieSession struct {
+	Expires time.Time
+	Values  map[string]any
+}
+
+// NewCookieStore creates a CookieStore that encrypts sessions with the given
+// key, which must be 16, 24, or 32 bytes long to select AES-128, AES-192, or AES-256.
+func NewCookieStore(key []byte) (*CookieStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("gong: cookie store: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("gong: cookie store: %w", err)
+	}
+	return &CookieStore{aead: aead}, nil
+}
+
+// Load decrypts and decodes the values in the cookie. Cookies that cannot be
+// decrypted, for example because they were tampered with or encrypted with
+// another key, and expired cookies are treated as empty sessions.
+func (store *CookieStore) Load(ctx context.Context, cookie string) (map[string]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cookie)
+	if err != nil || len(data) < store.aead.NonceSize() {
+		return map[string]any{}, nil
+	}
+	nonce, ciphertext := data[:store.aead.NonceSize()], data[store.aead.NonceSize():]
+	plaintext, err := store.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return map[string]any{}, nil
+	}
+	var session cookieSession
+	if err := gob.NewDecoder(bytes.NewReader(plaintext)).Decode(&session); err != nil {
+		return nil, fmt.Errorf("gong: cookie store: %w", err)
+	}
+	if time.Now().After(session.Expires) || session.Values == nil {
+		return map[string]any{}, nil
+	}
+	return session.Values, nil
+}
+
+// Save encodes and encrypts the values into a new cookie value.
+func (store *CookieStore) Save(ctx context.Context, cookie string, values map[string]any, maxAge time.Duration) (string, error) {
+	var plaintext bytes.Buffer
+	session := cookieSession{
+		Expires: time.Now().Add(maxAge),
+		Values:  values,
+	}
+	if err := gob.NewEncoder(&plaintext).Encode(session); err != nil {
+		return "", fmt.Errorf("gong: cookie store: %w", err)
+	}
+	nonce := make([]byte, store.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(store.aead.Seal(nonce, nonce, plaintext.Bytes(), nil))
+	if len(value) > maxCookieSize {
+		return "", errors.New("gong: cookie store: session is too large for a cookie")
+	}
+	return value, nil
+}
+
+// Delete does nothing, since the session only exists in the cookie, which is
+// expired by the caller.
+func (store *CookieStore) Delete(ctx context.Context, cookie string) error {
+	return nil
+}
@@ -0,0 +1,174 @@
+package gong
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/troygilman/gong/internal/assert"
+)
+
+func TestSession(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			value, _ := Session(ctx).Get("cart").(string)
+			flash, _ := Session(ctx).GetFlash("notice").(string)
+			_, err := io.WriteString(w, value+","+flash)
+			return err
+		}),
+		action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			Session(ctx).Set("cart", "apple")
+			Session(ctx).Flash("notice", "added")
+			return nil
+		}),
+	}, WithComponentID("mock"))))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestActionRequest(routeSegment("/"), "mock"))
+	assert.Equals(t, http.StatusOK, rec.Code)
+	cookie := testSessionCookie(t, rec)
+
+	view := func() string {
+		r := newTestRequest(http.MethodGet, "/")
+		r.AddCookie(cookie)
+		rec := httptest.NewRecorder()
+		svr.ServeHTTP(rec, r)
+		return rec.Body.String()
+	}
+
+	assert.Equals(t, true, strings.Contains(view(), "apple,added<"))
+	assert.Equals(t, true, strings.Contains(view(), "apple,<"))
+}
+
+func TestSession_delete(t *testing.T) {
+	store := NewMemoryStore()
+	id, err := store.Save(context.Background(), "", map[string]any{"user": "bob"}, time.Hour)
+	assert.NoErr(t, err)
+
+	svr := NewServer(WithSessions(SessionConfig{Store: store}))
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			Session(ctx).Delete("user")
+			return nil
+		}),
+	}, WithComponentID("mock"))))
+
+	r := newTestActionRequest(routeSegment("/"), "mock")
+	r.AddCookie(&http.Cookie{Name: defaultSessionCookieName, Value: id})
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	cookie := testSessionCookie(t, rec)
+	assert.Equals(t, -1, cookie.MaxAge)
+	values, err := store.Load(context.Background(), id)
+	assert.NoErr(t, err)
+	assert.Equals(t, 0, len(values))
+}
+
+func TestSession_renew(t *testing.T) {
+	store := NewMemoryStore()
+	id, err := store.Save(context.Background(), "", map[string]any{"cart": "apple"}, time.Hour)
+	assert.NoErr(t, err)
+
+	svr := NewServer(WithSessions(SessionConfig{Store: store}))
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			Session(ctx).Set("user", "bob")
+			Session(ctx).Renew()
+			return nil
+		}),
+	}, WithComponentID("mock"))))
+
+	r := newTestActionRequest(routeSegment("/"), "mock")
+	r.AddCookie(&http.Cookie{Name: defaultSessionCookieName, Value: id})
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	cookie := testSessionCookie(t, rec)
+	assert.Equals(t, false, cookie.Value == id)
+	values, err := store.Load(context.Background(), cookie.Value)
+	assert.NoErr(t, err)
+	assert.Equals(t, map[string]any{"cart": "apple", "user": "bob"}, values)
+	values, err = store.Load(context.Background(), id)
+	assert.NoErr(t, err)
+	assert.Equals(t, 0, len(values))
+}
+
+func TestMemoryStore_sweep(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	_, err := store.Save(ctx, "", map[string]any{"cart": "apple"}, -time.Second)
+	assert.NoErr(t, err)
+	_, err = store.Save(ctx, "", map[string]any{"cart": "pear"}, time.Hour)
+	assert.NoErr(t, err)
+	assert.Equals(t, 2, len(store.sessions))
+
+	store.nextSweep = time.Now().Add(-time.Second)
+	_, err = store.Save(ctx, "", map[string]any{"cart": "plum"}, time.Hour)
+	assert.NoErr(t, err)
+	assert.Equals(t, 2, len(store.sessions))
+	assert.Equals(t, true, store.nextSweep.After(time.Now()))
+}
+
+func TestSession_unmodified(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		view: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			_ = Session(ctx).Get("cart")
+			return nil
+		}),
+	})))
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, newTestRequest(http.MethodGet, "/"))
+
+	for _, cookie := range rec.Result().Cookies() {
+		assert.Equals(t, false, cookie.Name == defaultSessionCookieName)
+	}
+}
+
+func TestCookieStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))
+	assert.NoErr(t, err)
+
+	cookie, err := store.Save(ctx, "", map[string]any{"cart": "apple", "count": 2}, time.Hour)
+	assert.NoErr(t, err)
+
+	values, err := store.Load(ctx, cookie)
+	assert.NoErr(t, err)
+	assert.Equals(t, map[string]any{"cart": "apple", "count": 2}, values)
+
+	tampered := []byte(cookie)
+	tampered[len(tampered)-1] ^= 1
+	values, err = store.Load(ctx, string(tampered))
+	assert.NoErr(t, err)
+	assert.Equals(t, 0, len(values))
+
+	expired, err := store.Save(ctx, "", map[string]any{"cart": "apple"}, -time.Second)
+	assert.NoErr(t, err)
+	values, err = store.Load(ctx, expired)
+	assert.NoErr(t, err)
+	assert.Equals(t, 0, len(values))
+}
+
+func TestNewCookieStore_invalidKey(t *testing.T) {
+	_, err := NewCookieStore([]byte("short"))
+	assert.Err(t, err)
+}
+
+func testSessionCookie(t *testing.T, rec *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == defaultSessionCookieName {
+			return cookie
+		}
+	}
+	t.Fatal("no session cookie set")
+	return nil
+}
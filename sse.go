@@ -0,0 +1,303 @@
+package gong
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/a-h/templ"
+	"github.com/troygilman/gong/internal/response_writer"
+)
+
+const (
+	ssePath = "/_gong/sse"
+	// sseBufferSize is the number of events buffered per subscriber.
+	// Events published to a subscriber with a full buffer are dropped.
+	sseBufferSize = 16
+)
+
+// SubscribeAuthorizer decides whether a client may subscribe to a topic.
+// It runs after the middleware and guards of the route of the subscribing
+// Target, so it can use the same request context, and returns nil to allow
+// the subscription or an error, such as ErrForbidden, to reject it.
+type SubscribeAuthorizer func(ctx context.Context, topic string) error
+
+// sseEvent is a server-sent event pushed to subscribers of a topic.
+type sseEvent struct {
+	topic string
+	data  string
+}
+
+// sseSubscriber is a client streaming the events of a topic into a Target.
+type sseSubscriber struct {
+	// ctx is the context of the stream request, carrying the gongContext of
+	// the Target's component that published components are rendered with.
+	ctx    context.Context
+	swap   string
+	events chan sseEvent
+}
+
+// send queues the event without blocking, dropping it if the buffer is full.
+func (sub *sseSubscriber) send(event sseEvent) {
+	select {
+	case sub.events <- event:
+	default:
+	}
+}
+
+// sseBroker tracks the clients subscribed to each topic.
+type sseBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*sseSubscriber]struct{}
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+func newSSEBroker() *sseBroker {
+	return &sseBroker{
+		subscribers: make(map[string]map[*sseSubscriber]struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// close ends every event stream, including streams opened afterwards.
+// http.Server.Shutdown does not cancel the context of active requests, so
+// streams must be ended this way for a shutdown to complete.
+func (broker *sseBroker) close() {
+	broker.closeOnce.Do(func() {
+		close(broker.done)
+	})
+}
+
+func (broker *sseBroker) subscribe(topic string, sub *sseSubscriber) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	if broker.subscribers[topic] == nil {
+		broker.subscribers[topic] = make(map[*sseSubscriber]struct{})
+	}
+	broker.subscribers[topic][sub] = struct{}{}
+}
+
+func (broker *sseBroker) unsubscribe(topic string, sub *sseSubscriber) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	delete(broker.subscribers[topic], sub)
+	if len(broker.subscribers[topic]) == 0 {
+		delete(broker.subscribers, topic)
+	}
+}
+
+func (broker *sseBroker) hasSubscribers(topic string) bool {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	return len(broker.subscribers[topic]) > 0
+}
+
+// subscribersOf returns the clients currently subscribed to the topic.
+func (broker *sseBroker) subscribersOf(topic string) []*sseSubscriber {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	subs := make([]*sseSubscriber, 0, len(broker.subscribers[topic]))
+	for sub := range broker.subscribers[topic] {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// stream writes the events sent to the subscriber until the client
+// disconnects or the broker is closed.
+func (broker *sseBroker) stream(w http.ResponseWriter, r *http.Request, topic string, sub *sseSubscriber) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	broker.subscribe(topic, sub)
+	defer broker.unsubscribe(topic, sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-broker.done:
+			return
+		case event := <-sub.events:
+			if _, err := w.Write(event.encode()); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// encode formats the event in the text/event-stream format.
+func (event sseEvent) encode() []byte {
+	buffer := new(bytes.Buffer)
+	fmt.Fprintf(buffer, "event: %s\n", event.topic)
+	for _, line := range strings.Split(event.data, "\n") {
+		fmt.Fprintf(buffer, "data: %s\n", line)
+	}
+	buffer.WriteString("\n")
+	return buffer.Bytes()
+}
+
+// subscribeHandler returns the handler of the event streams of WithSubscribe
+// Targets. A subscription is resolved like an action request: the route and
+// component of the Target are named by its query parameters, and the route's
+// middleware and guards and the server's SubscribeAuthorizer run before any
+// event is streamed. Sessions can be read while rendering pushed components,
+// but changes to them are not saved.
+func (svr *Server) subscribeHandler(root *routeNode) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		topic := query.Get("topic")
+		gCtx := gongContext{
+			Request:         r,
+			RouteID:         query.Get("route"),
+			ComponentID:     query.Get("component"),
+			FieldErrors:     ValidationErrors{},
+			MultipartMemory: svr.multipartMemory,
+			BindLimits:      svr.bindLimits,
+			ErrorHandler:    svr.errorHandler,
+		}
+
+		if topic == "" || gCtx.RouteID == "" || gCtx.ComponentID == "" {
+			svr.handleError(w, r, gCtx, fmt.Errorf("%w: subscriptions require the topic, route and component query parameters", ErrBadGongHeaders))
+			return
+		}
+		target, err := findTarget(root, gCtx.RouteID, gCtx.ComponentID)
+		if err != nil {
+			svr.handleError(w, r, gCtx, err)
+			return
+		}
+		if svr.csrf != nil {
+			token, _, err := svr.csrf.csrfToken(w, r)
+			if err != nil {
+				svr.handleError(w, r, gCtx, err)
+				return
+			}
+			gCtx.CSRFToken = token
+		}
+		gCtx.Session = newSession(svr.sessions, r, sessionErrorHandler(gCtx))
+		gCtx.Node = target
+
+		var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if svr.authorizeSubscribe != nil {
+				if err := svr.authorizeSubscribe(r.Context(), topic); err != nil {
+					svr.handleError(w, r, getContext(r.Context()), err)
+					return
+				}
+			}
+			svr.broker.stream(w, r, topic, &sseSubscriber{
+				ctx:    r.Context(),
+				swap:   pushSwap(query.Get("swap")),
+				events: make(chan sseEvent, sseBufferSize),
+			})
+		})
+		if len(target.guards) > 0 {
+			handler = svr.guardHandler(handler, target.guards)
+		}
+		handler = chainMiddleware(handler, target.middleware)
+
+		handler.ServeHTTP(w, r.WithContext(setContext(r.Context(), gCtx)))
+	})
+}
+
+// pushSwap returns the out of band swap strategy that applies pushed content
+// to a Target the way its hx-swap value applies responses. Swap modifiers are
+// dropped, and outerHTML, which would replace the Target and end its
+// subscription, is applied as innerHTML.
+func pushSwap(swap string) string {
+	fields := strings.Fields(swap)
+	if len(fields) == 0 || fields[0] == SwapOuterHTML {
+		return SwapInnerHTML
+	}
+	return fields[0]
+}
+
+// Publish renders the component and pushes it to every client subscribed to
+// the topic with a WithSubscribe Target, as an out of band swap into that
+// Target using its swap strategy. The component is rendered for each client
+// in the context of the subscribing Target's route and component, so Buttons,
+// Forms and Targets in it send actions to that component as if it had been
+// rendered in the page. Elements in the component marked with hx-swap-oob are
+// swapped out of band as well.
+// Publish does not block on slow clients; events that a client cannot keep up with are dropped.
+// Events carry no response headers, so headers set by the component, such as
+// with Trigger or Emit, are discarded.
+// It returns the errors of rendering for any client joined together, including
+// panics, which are recovered.
+func (svr *Server) Publish(topic string, component templ.Component) error {
+	if _, err := svr.Build(); err != nil {
+		return err
+	}
+	var errs []error
+	for _, sub := range svr.broker.subscribersOf(topic) {
+		data, err := sub.render(component)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sub.send(sseEvent{
+			topic: topic,
+			data:  data,
+		})
+	}
+	return errors.Join(errs...)
+}
+
+// render renders component for the subscriber as an out of band swap into its Target.
+func (sub *sseSubscriber) render(component templ.Component) (data string, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("gong: panic: %v", v)
+		}
+	}()
+	buffer := new(bytes.Buffer)
+	gCtx := getContext(sub.ctx)
+	gCtx.Writer = response_writer.NewResponseWriter(discardResponseWriter{header: http.Header{}})
+	if err := render(templ.WithChildren(sub.ctx, component), gCtx, buffer, oobWrapper(ComponentID(sub.ctx), sub.swap)); err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+// discardResponseWriter is an http.ResponseWriter that discards everything
+// written to it, for rendering outside of a response.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (discardResponseWriter) WriteHeader(int) {}
+
+// subscribeURL returns the URL of the event stream of a topic for a Target
+// of the component being rendered, which swaps in pushed content with swap.
+func subscribeURL(ctx context.Context, topic string, swap string) string {
+	gCtx := getContext(ctx)
+	return ssePath + "?" + url.Values{
+		"topic":     {topic},
+		"route":     {gCtx.Node.id},
+		"component": {gCtx.ComponentID},
+		"swap":      {swap},
+	}.Encode()
+}
@@ -0,0 +1,201 @@
+package gong
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/troygilman/gong/internal/assert"
+)
+
+// testSubscribeURL returns the URL of the event stream of the topic for the
+// root component of the "/" route.
+func testSubscribeURL(topic string) string {
+	return ssePath + "?" + url.Values{
+		"topic":     {topic},
+		"route":     {routeSegment("/")},
+		"component": {"root"},
+	}.Encode()
+}
+
+// readSSEEvent reads the lines of the next event from the stream.
+func readSSEEvent(t *testing.T, reader *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		assert.NoErr(t, err)
+		if line == "\n" {
+			return lines
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+}
+
+func TestServerPublish(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{})))
+
+	ts := httptest.NewServer(svr)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + testSubscribeURL("orders") + "&swap=beforeend")
+	assert.NoErr(t, err)
+	defer res.Body.Close()
+	assert.Equals(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	for !svr.broker.hasSubscribers("orders") {
+		time.Sleep(time.Millisecond)
+	}
+	assert.NoErr(t, svr.Publish("orders", testTemplComponent{text: "line 1\nline 2"}))
+
+	lines := readSSEEvent(t, bufio.NewReader(res.Body))
+	assert.Equals(t, []string{
+		"event: orders",
+		`data: <div id="gong_` + routeSegment("/") + `_root" hx-swap-oob="beforeend">line 1`,
+		"data: line 2</div>",
+	}, lines)
+}
+
+func TestServerPublish_actionContext(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{})))
+
+	ts := httptest.NewServer(svr)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + testSubscribeURL("orders"))
+	assert.NoErr(t, err)
+	defer res.Body.Close()
+
+	for !svr.broker.hasSubscribers("orders") {
+		time.Sleep(time.Millisecond)
+	}
+	assert.NoErr(t, svr.Publish("orders", Button()))
+
+	data := strings.Join(readSSEEvent(t, bufio.NewReader(res.Body)), "\n")
+	assert.Equals(t, true, strings.Contains(data, `hx-swap-oob="innerHTML"`))
+	assert.Equals(t, true, strings.Contains(data, `&#34;Gong-Route-ID&#34;: &#34;`+routeSegment("/")+`&#34;`))
+	assert.Equals(t, true, strings.Contains(data, `&#34;Gong-Component-ID&#34;: &#34;root&#34;`))
+	for _, cookie := range res.Cookies() {
+		if cookie.Name == defaultCSRFCookieName {
+			assert.Equals(t, true, strings.Contains(data, `&#34;Gong-CSRF-Token&#34;: &#34;`+cookie.Value+`&#34;`))
+			return
+		}
+	}
+	t.Fatal("no CSRF cookie issued to the subscriber")
+}
+
+func TestServerSubscribe_rejected(t *testing.T) {
+	svr := NewServer(WithSubscribeAuthorizer(func(ctx context.Context, topic string) error {
+		if topic == "secret" {
+			return ErrForbidden
+		}
+		return nil
+	}))
+	svr.Route(NewRoute("/", NewComponent(testComponent{}), WithChildren(
+		NewRoute("admin", NewComponent(testComponent{}), WithGuard(func(ctx context.Context) error {
+			return ErrForbidden
+		})),
+	)))
+
+	tests := []struct {
+		name string
+		url  string
+		code int
+	}{
+		{name: "missing route", url: ssePath + "?topic=orders", code: http.StatusBadRequest},
+		{name: "unknown component", url: strings.Replace(testSubscribeURL("orders"), "component=root", "component=other", 1), code: http.StatusNotFound},
+		{name: "unauthorized topic", url: testSubscribeURL("secret"), code: http.StatusForbidden},
+		{
+			name: "guarded route",
+			url: ssePath + "?" + url.Values{
+				"topic":     {"orders"},
+				"route":     {joinRouteID(routeSegment("/"), routeSegment("admin"))},
+				"component": {"root"},
+			}.Encode(),
+			code: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			svr.ServeHTTP(rec, newTestRequest(http.MethodGet, tt.url))
+
+			assert.Equals(t, tt.code, rec.Code)
+			assert.Equals(t, false, svr.broker.hasSubscribers("orders"))
+		})
+	}
+}
+
+func TestServerPublish_renderError(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{})))
+
+	ts := httptest.NewServer(svr)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + testSubscribeURL("orders"))
+	assert.NoErr(t, err)
+	defer res.Body.Close()
+
+	for !svr.broker.hasSubscribers("orders") {
+		time.Sleep(time.Millisecond)
+	}
+	broken := RenderFunc(func(ctx context.Context, w io.Writer) error {
+		return errors.New("broken")
+	})
+	assert.Err(t, svr.Publish("orders", broken))
+}
+
+func TestServerPublish_responseHelpers(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{})))
+
+	ts := httptest.NewServer(svr)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + testSubscribeURL("orders"))
+	assert.NoErr(t, err)
+	defer res.Body.Close()
+
+	for !svr.broker.hasSubscribers("orders") {
+		time.Sleep(time.Millisecond)
+	}
+	triggering := RenderFunc(func(ctx context.Context, w io.Writer) error {
+		Header(ctx).Set("X-Order", "1")
+		if err := Trigger(ctx, "order.created", 1); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "order")
+		return err
+	})
+	assert.NoErr(t, svr.Publish("orders", triggering))
+	lines := readSSEEvent(t, bufio.NewReader(res.Body))
+	assert.Equals(t, true, strings.Contains(strings.Join(lines, "\n"), "order</div>"))
+
+	panicking := RenderFunc(func(ctx context.Context, w io.Writer) error {
+		panic("broken")
+	})
+	assert.Err(t, svr.Publish("orders", panicking))
+}
+
+func TestTargetSubscribe(t *testing.T) {
+	node := NewRoute("/", NewComponent(testComponent{
+		view: Target(WithSubscribe("orders")),
+	})).newNode(nil)
+
+	buffer := new(bytes.Buffer)
+	err := render(context.Background(), gongContext{Request: newTestRequest(http.MethodGet, "/")}, buffer, node)
+
+	assert.NoErr(t, err)
+	assert.Equals(t, true, strings.Contains(buffer.String(), `<div hx-ext="sse" sse-connect="/_gong/sse?component=root&amp;route=&amp;swap=innerHTML&amp;topic=orders" sse-swap="orders" hx-swap="none" style="display: contents"><div id="gong__root"`))
+}
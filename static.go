@@ -0,0 +1,56 @@
+package gong
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// staticFS holds the assets Gong serves itself, such as the htmx build.
+//
+//go:embed static
+var staticFS embed.FS
+
+const (
+	staticPath    = "/_gong/static/"
+	htmxVersion   = "2.0.4"
+	htmxFile      = "htmx-" + htmxVersion + ".min.js"
+	htmxIntegrity = "sha384-HGfztofotfshcF7+8n44JQL2oJmowVChPTg48S+jvZoztPfvwD79OC/LTtG6dMp+"
+	sseExtVersion = "2.2.3"
+	sseExtFile    = "htmx-ext-sse-" + sseExtVersion + ".js"
+)
+
+// script is a script element rendered in the document head.
+type script struct {
+	src       string
+	integrity string
+}
+
+// defaultHTMXScript returns the htmx script served from the embedded assets.
+// Gong never loads htmx from a CDN on its own; use WithHTMXSource for that.
+func defaultHTMXScript() script {
+	return script{
+		src:       staticPath + htmxFile,
+		integrity: htmxIntegrity,
+	}
+}
+
+// defaultExtensionScripts returns the scripts of the embedded htmx extensions.
+// The SSE extension is required by Targets using WithSubscribe.
+func defaultExtensionScripts() []script {
+	return []script{{src: staticPath + sseExtFile}}
+}
+
+// staticHandler serves the embedded assets under staticPath.
+// Asset file names include their version, so responses are marked immutable.
+func staticHandler() http.Handler {
+	assets, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		panic(err)
+	}
+	fileServer := http.StripPrefix(staticPath, http.FileServerFS(assets))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		fileServer.ServeHTTP(w, r)
+	})
+}
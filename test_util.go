@@ -93,3 +93,18 @@ func newTestRequest(method string, url string) *http.Request {
 	}
 	return r
 }
+
+const testCSRFToken = "dGVzdC1jc3JmLXRva2VuLXRlc3QtY3NyZi10b2tlbjA"
+
+// newTestActionRequest creates a POST action request for the component with
+// the given ID in the route with the given ID. The request carries a matching
+// CSRF cookie and header so it passes the server's CSRF check.
+func newTestActionRequest(routeID string, componentID string) *http.Request {
+	r := newTestRequest(http.MethodPost, "/")
+	r.Header.Set(HeaderGongRequestType, GongRequestTypeAction)
+	r.Header.Set(HeaderGongRouteID, routeID)
+	r.Header.Set(HeaderGongComponentID, componentID)
+	r.Header.Set(HeaderGongCSRFToken, testCSRFToken)
+	r.AddCookie(&http.Cookie{Name: defaultCSRFCookieName, Value: testCSRFToken})
+	return r
+}
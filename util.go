@@ -1,36 +1,64 @@
 package gong
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
+	"log"
+	"net/http"
+	"strings"
 
 	"github.com/a-h/templ"
 )
 
 // Render renders a templ component with the provided Gong context.
 // This is an internal utility used by components, routes, and other elements
-// to consistently render with the Gong context. It handles error propagation
-// and custom error handling if provided.
+// to consistently render with the Gong context. Errors are propagated to the
+// caller so the server can report them through its ErrorHandler.
 func render(ctx context.Context, gCtx gongContext, w io.Writer, component templ.Component) error {
 	if component == nil {
-		panic("cannot render nil templ.Component")
+		return errors.New("gong: cannot render nil templ.Component")
 	}
-	ctx = setContext(ctx, gCtx)
-	err := component.Render(ctx, w)
-	if err != nil && gCtx.ErrorHandler != nil {
-		gCtx.ErrorHandler(ctx, err)
-		return nil
+	return component.Render(setContext(ctx, gCtx), w)
+}
+
+// renderWithFallback renders component, and if it returns an error, reports the
+// error to the ErrorHandler and renders fallback in its place instead.
+// Output written by the failed component is discarded.
+// The error is available to the fallback through RenderError.
+// Without a fallback, errors are returned to the caller.
+func renderWithFallback(ctx context.Context, gCtx gongContext, w io.Writer, component templ.Component, fallback templ.Component) error {
+	if fallback == nil {
+		return render(ctx, gCtx, w, component)
+	}
+	buffer := new(bytes.Buffer)
+	if err := render(ctx, gCtx, buffer, component); err != nil {
+		gCtx.Err = err
+		if gCtx.ErrorHandler != nil {
+			gCtx.ErrorHandler(setContext(ctx, gCtx), err)
+		} else {
+			log.Println(err)
+		}
+		return render(ctx, gCtx, w, fallback)
 	}
+	_, err := buffer.WriteTo(w)
 	return err
 }
 
+// isHTMXRequest reports whether the request was sent by htmx.
+func isHTMXRequest(r *http.Request) bool {
+	return r != nil && r.Header.Get(HeaderHXRequest) == "true"
+}
+
 // GongHeaders generates the standard set of Gong HTTP headers for a request.
-// These headers are used to identify the request type, route ID, and component ID,
+// These headers are used to identify the request type, route ID, component ID and CSRF token,
 // which allows the server to properly handle the request and route it to the
 // correct component.
 func gongHeaders(ctx context.Context, requestType string) []string {
 	gCtx := getContext(ctx)
-	return []string{
+	headers := []string{
 		HeaderGongRequestType,
 		requestType,
 		HeaderGongRouteID,
@@ -38,4 +66,23 @@ func gongHeaders(ctx context.Context, requestType string) []string {
 		HeaderGongComponentID,
 		gCtx.ComponentID,
 	}
+	if gCtx.CSRFToken != "" {
+		headers = append(headers, HeaderGongCSRFToken, gCtx.CSRFToken)
+	}
+	return headers
+}
+
+// linkURL returns the URL for a Link. If path is the name of a route, the
+// route's URL is built from params. Otherwise path must be absolute, or
+// explicitly relative with a "./" or "../" prefix, and is returned unchanged,
+// so a misspelled route name is reported as an error wrapping
+// ErrUnknownRouteName instead of being rendered as a relative link.
+func linkURL(ctx context.Context, path string, params []string) (string, error) {
+	if _, ok := getContext(ctx).Node.root().names[path]; ok {
+		return URL(ctx, path, params...)
+	}
+	if !strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "./") && !strings.HasPrefix(path, "../") {
+		return "", fmt.Errorf("%w: %q, and link paths must start with \"/\", \"./\" or \"../\"", ErrUnknownRouteName, path)
+	}
+	return path, nil
 }
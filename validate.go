@@ -0,0 +1,32 @@
+package gong
+
+import (
+	"context"
+
+	"github.com/troygilman/gong/internal/validate"
+)
+
+// ValidationErrors is returned by Bind when fields fail validation.
+// It maps the form key of each invalid field, such as "email" or
+// "person[email]", to its error message.
+type ValidationErrors = validate.Errors
+
+// ValidatorFunc checks a field value against a rule in a validate tag.
+// The param is the text after "=" in the rule, such as "3" in "min=3".
+// The message of the returned error is reported for the field, so it should
+// complete a sentence about the field, for example "must be a valid email address".
+type ValidatorFunc = validate.Func
+
+// RegisterValidator makes a custom rule available to validate tags under the given name.
+// The built-in rules are required, email, min, max, len, and oneof.
+// Registering an existing name replaces its validator.
+func RegisterValidator(name string, fn ValidatorFunc) {
+	validate.Register(name, fn)
+}
+
+// FieldError returns the error message for the field with the given form key
+// from the calls to Bind in this request, or an empty string if the field is
+// valid. Messages come from ValidationErrors and BindErrors alike.
+func FieldError(ctx context.Context, key string) string {
+	return getContext(ctx).FieldErrors[key]
+}
@@ -0,0 +1,45 @@
+package gong
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/troygilman/gong/internal/assert"
+)
+
+type testSignupForm struct {
+	Name  string `form:"name" validate:"required"`
+	Email string `form:"email" validate:"required,email"`
+}
+
+func TestBind_validation(t *testing.T) {
+	svr := NewServer()
+	svr.Route(NewRoute("/", NewComponent(testComponent{
+		action: RenderFunc(func(ctx context.Context, w io.Writer) error {
+			var form testSignupForm
+			err := Bind(ctx, &form)
+			var errs ValidationErrors
+			if err != nil && !errors.As(err, &errs) {
+				return err
+			}
+			_, err = io.WriteString(w, "name:"+FieldError(ctx, "name")+" email:"+FieldError(ctx, "email"))
+			return err
+		}),
+	}, WithComponentID("mock"))))
+
+	r := newTestActionRequest(routeSegment("/"), "mock")
+	r.Body = io.NopCloser(strings.NewReader(url.Values{"email": {"nope"}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := httptest.NewRecorder()
+	svr.ServeHTTP(rec, r)
+
+	assert.Equals(t, http.StatusOK, rec.Code)
+	assert.Equals(t, "name:is required email:must be a valid email address", rec.Body.String())
+}